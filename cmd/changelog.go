@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"sykesdev.ca/gog/internal/changelog"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/conventional"
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/output"
+	"sykesdev.ca/gog/internal/semver"
+)
+
+type ChangelogCommand struct {
+	fs *flag.FlagSet
+
+	name string
+	alias string
+	action string
+	since string
+	component string
+	dryRun bool
+	major bool
+	minor bool
+	patch bool
+	preRelease string
+	format string
+}
+
+type changelogRebuild struct {
+	File string `json:"file"`
+	Since string `json:"since,omitempty"`
+	Rebuilt []string `json:"rebuilt"`
+	Kept []string `json:"kept"`
+	Written bool `json:"written"`
+	Changelog string `json:"changelog,omitempty"`
+}
+
+type changelogUpgrade struct {
+	File string `json:"file"`
+	Header bool `json:"header"`
+	Releases []string `json:"releases"`
+	Written bool `json:"written"`
+	Changelog string `json:"changelog,omitempty"`
+}
+
+type changelogSection struct {
+	Name string `json:"name"`
+	Changes []string `json:"changes"`
+}
+
+type changelogPreview struct {
+	Ticket string `json:"ticket"`
+	Component string `json:"component,omitempty"`
+	Action FinishAction `json:"action"`
+	Version string `json:"version"`
+	Date string `json:"date"`
+	Sections []changelogSection `json:"sections"`
+	Markdown string `json:"markdown"`
+}
+
+const (
+	formatMarkdown = "md"
+	formatText = "text"
+	formatJSON = "json"
+)
+
+var markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+
+func NewChangelogCommand() *ChangelogCommand {
+	cc := &ChangelogCommand{
+		name: "changelog",
+		alias: "cl",
+		fs: flag.NewFlagSet("changelog", flag.ContinueOnError),
+	}
+
+	cc.fs.StringVar(&cc.since, "since", "", "keeps the entries from this release tag back as they are and only rebuilds the newer ones")
+	cc.fs.StringVar(&cc.component, "component", "", "rebuilds or upgrades the changelog of the named monorepo component (from its own tags)")
+	cc.fs.BoolVar(&cc.dryRun, "dry-run", false, "prints the rebuilt or upgraded changelog instead of writing it")
+	cc.fs.BoolVar(&cc.major, "major", false, "previews the entry for a major release. the release type is inferred from the conventional commits on the branch by default")
+	cc.fs.BoolVar(&cc.minor, "minor", false, "previews the entry for a minor release")
+	cc.fs.BoolVar(&cc.patch, "patch", false, "previews the entry for a patch release")
+	cc.fs.StringVar(&cc.preRelease, "pre", "", "previews the entry for a pre-release with the given identifier (eg. 'rc')")
+	cc.fs.StringVar(&cc.format, "format", formatMarkdown, "renders the preview as md (markdown), text or json")
+
+	cc.fs.Usage = cc.Help
+
+	return cc
+}
+
+func (cc *ChangelogCommand) Help() {
+	fmt.Printf(
+`Usage: %s (%s | %s) (preview | unreleased | rebuild | upgrade) [options ...] [-h] [-help]
+
+-------====== Changelog Arguments ======-------
+
+preview | unreleased [-major | -minor | -patch] [-pre id] [-component name] [-format md|text|json]
+	renders the entry finish would write for the feature on the current branch right now, without changing anything
+rebuild [-since <tag>] [-component name] [-dry-run]
+	regenerates the changelog from the release tags merged into the default branch, using the commits and tag message of each release.
+	the header and the Unreleased block of the existing changelog are kept
+upgrade [-component name] [-dry-run]
+	migrates the header and the '## [ v1.2.3 ] - 2022-3-4 5:6:7' release headings written by older versions of GOG to the current format
+
+------================================------
+
+`, os.Args[0], cc.name, cc.alias)
+
+	cc.fs.PrintDefaults()
+
+	fmt.Println("\n-------================================-------")
+}
+
+func (cc *ChangelogCommand) Init(args []string) error {
+	if len(args) < 1 {
+		return errors.New("invalid usage of changelog command. must pass an action (re-run with -h for full usage details)")
+	}
+
+	cc.action = args[0]
+
+	if err := cc.fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if cc.fs.NArg() > 0 {
+		return fmt.Errorf("invalid usage of changelog %s. unexpected argument '%s' (re-run with -h for full usage details)", cc.action, cc.fs.Arg(0))
+	}
+
+	if !common.StringInSlice([]string{formatMarkdown, formatText, formatJSON}, cc.format) {
+		return fmt.Errorf("unknown format '%s'. must be one of %s, %s, %s", cc.format, formatMarkdown, formatText, formatJSON)
+	}
+
+	if cc.preRelease != "" && !semver.ValidPreReleaseID(cc.preRelease) {
+		return fmt.Errorf("invalid pre-release identifier '%s'. must be a single identifier of alphanumerics and hyphens (eg. 'rc'), without dots or leading zeros", cc.preRelease)
+	}
+
+	return nil
+}
+
+func (cc *ChangelogCommand) Run() (interface{}, error) {
+	switch cc.action {
+	case "preview", "unreleased":
+		return cc.preview()
+	case "rebuild":
+		return cc.rebuild()
+	case "upgrade":
+		return cc.upgrade()
+	default:
+		return nil, output.Errorf(output.CodeUsage, "unknown changelog action: %s (re-run with -h for full usage details)", cc.action)
+	}
+}
+
+// preview renders the entry through the same code path as finish, including anything it would take over from the Unreleased block
+func (cc *ChangelogCommand) preview() (interface{}, error) {
+	feature, err := loadReleaseFeature(cc.component)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := git.NewRepository()
+	if err != nil {
+		return nil, err
+	}
+	*r.FeatureBranch = *r.CurrentBranch
+
+	action, err := cc.previewAction(r, feature)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := nextReleaseVersion(r.LastTag, action, cc.preRelease)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, _, err := releaseEntry(r, feature, action, version, false)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := entry.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &changelogPreview{
+		Ticket: feature.Ticket,
+		Component: feature.Component,
+		Action: action,
+		Version: release.Version,
+		Date: release.Date,
+		Sections: []changelogSection{},
+		Markdown: entry.String(),
+	}
+	for _, section := range release.Sections {
+		s := changelogSection{Name: section.Name, Changes: []string{}}
+		for _, line := range section.Lines {
+			if line = strings.TrimSpace(line); line != "" {
+				s.Changes = append(s.Changes, strings.TrimPrefix(line, "- "))
+			}
+		}
+		result.Sections = append(result.Sections, s)
+	}
+
+	switch cc.format {
+	case formatJSON:
+		previewBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode changelog preview. %v", err)
+		}
+		fmt.Fprintln(output.Stdout(), string(previewBytes))
+	case formatText:
+		fmt.Fprint(output.Stdout(), previewText(release))
+	default:
+		fmt.Fprint(output.Stdout(), result.Markdown)
+	}
+
+	return result, nil
+}
+
+// previewAction is the release type given by flags, or otherwise the one inferred from the conventional commits on the branch
+func (cc *ChangelogCommand) previewAction(r *git.Repository, feature *models.Feature) (FinishAction, error) {
+	switch {
+	case cc.major:
+		return "MAJOR", nil
+	case cc.minor:
+		return "MINOR", nil
+	case cc.patch:
+		return "PATCH", nil
+	}
+
+	commits, err := r.FeatureBranch.RelatedCommits()
+	if err != nil {
+		return "", output.Errorf(output.CodeGit, "failed to read commits for %s. %v", r.FeatureBranch, err)
+	}
+
+	level, _ := conventional.InferBump(conventional.ParseAll(commits, feature.Ticket))
+	return FinishAction(level), nil
+}
+
+// previewText renders a release without markdown, eg. for a terminal or a chat message
+func previewText(release *changelog.Release) string {
+	plain := func(line string) string {
+		return strings.ReplaceAll(markdownLink.ReplaceAllString(line, "$1"), "`", "")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s - %s\n", release.Version, release.Date)
+	for _, line := range release.Intro {
+		if line = strings.TrimSpace(line); line != "" {
+			fmt.Fprintln(&b, plain(strings.TrimSpace(strings.TrimPrefix(line, ">"))))
+		}
+	}
+	for _, section := range release.Sections {
+		fmt.Fprintf(&b, "\n%s:\n", section.Name)
+		for _, line := range section.Lines {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintf(&b, "  %s\n", plain(line))
+			}
+		}
+	}
+	return b.String()
+}
+
+func (cc *ChangelogCommand) rebuild() (interface{}, error) {
+	if cc.component != "" {
+		if _, err := selectComponent(cc.component); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := git.NewRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := changelog.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s. %v", changelog.File(), err)
+	}
+
+	c, rebuilt, err := changelog.Rebuild(r, existing, cc.since)
+	if err != nil {
+		return nil, output.WithCode(output.CodeGit, err)
+	}
+
+	result := &changelogRebuild{File: changelog.File(), Since: cc.since, Rebuilt: append([]string{}, rebuilt...), Kept: []string{}}
+	for _, release := range c.Releases[1+len(rebuilt):] {
+		result.Kept = append(result.Kept, release.Version)
+	}
+
+	if cc.dryRun {
+		result.Changelog = c.String()
+		fmt.Fprint(output.Stdout(), result.Changelog)
+		logging.Instance().Info("dry-run complete ... the changelog was not written")
+		return result, nil
+	}
+
+	if err := changelog.WriteChangelogToFile(c.Lines()); err != nil {
+		return nil, fmt.Errorf("failed to write %s. %v", changelog.File(), err)
+	}
+	result.Written = true
+
+	logging.Instance().Infof("rebuilt %s with %d release(s)", changelog.File(), len(result.Rebuilt))
+
+	return result, nil
+}
+
+// upgrade migrates the parts of the changelog written by older versions of GOG, which finish leaves as they are
+func (cc *ChangelogCommand) upgrade() (interface{}, error) {
+	if cc.component != "" {
+		if _, err := selectComponent(cc.component); err != nil {
+			return nil, err
+		}
+	}
+
+	c, err := changelog.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s. %v", changelog.File(), err)
+	}
+
+	header, releases, err := c.Upgrade()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &changelogUpgrade{File: changelog.File(), Header: header, Releases: releases}
+	if !header && len(releases) == 0 {
+		logging.Instance().Infof("%s is already in the current format ... nothing to upgrade", changelog.File())
+		return result, nil
+	}
+
+	if cc.dryRun {
+		result.Changelog = c.String()
+		fmt.Fprint(output.Stdout(), result.Changelog)
+		logging.Instance().Info("dry-run complete ... the changelog was not written")
+		return result, nil
+	}
+
+	if err := changelog.WriteChangelogToFile(c.Lines()); err != nil {
+		return nil, fmt.Errorf("failed to write %s. %v", changelog.File(), err)
+	}
+	result.Written = true
+
+	if header {
+		logging.Instance().Infof("upgraded the header of %s", changelog.File())
+	}
+	logging.Instance().Infof("upgraded the headings of %d release(s) in %s", len(releases), changelog.File())
+
+	return result, nil
+}
+
+func (cc *ChangelogCommand) Name() string {
+	return cc.name
+}
+
+func (cc *ChangelogCommand) Alias() string {
+	return cc.alias
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
+)
+
+// selectComponent activates the monorepo component a feature is released for. without a name it is inferred from the paths changed on the current branch
+func selectComponent(name string) (string, error) {
+	if name == "" {
+		if len(config.AppConfig().Components()) == 0 {
+			return "", nil
+		}
+
+		inferred, err := inferComponent(git.DefaultBackend())
+		if err != nil {
+			return "", err
+		}
+		name = inferred
+	}
+
+	if err := config.AppConfig().SetComponent(name); err != nil {
+		return "", output.WithCode(output.CodeConfig, err)
+	}
+
+	if name != "" {
+		logging.Instance().Debugf("releasing component %s with tag prefix '%s'", name, config.AppConfig().TagPrefix())
+	}
+
+	return name, nil
+}
+
+// inferComponent finds the single component owning the changes on the current branch. changes outside of every component are released for the whole repository
+func inferComponent(b git.Backend) (string, error) {
+	defaultBranch, err := b.DefaultBranch()
+	if err != nil {
+		return "", output.Errorf(output.CodeGit, "failed to read the default branch to infer the component. %v", err)
+	}
+
+	files, err := b.ChangedFiles("refs/remotes/origin/" + defaultBranch)
+	if err != nil {
+		return "", output.Errorf(output.CodeGit, "failed to read changed files to infer the component. %v", err)
+	}
+
+	owners := map[string]bool{}
+	for _, f := range files {
+		if component, ok := componentForPath(f); ok {
+			owners[component.Name] = true
+		}
+	}
+
+	var names []string
+	for name := range owners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch len(names) {
+	case 0:
+		logging.Instance().Debugf("no component owns the %d changed file(s), releasing the repository", len(files))
+		return "", nil
+	case 1:
+		logging.Instance().Infof("inferred component %s from the changed files", names[0])
+		return names[0], nil
+	}
+
+	return "", output.Errorf(output.CodeUsage, "the changes span components %s. select one with -component", strings.Join(names, ", "))
+}
+
+// componentForPath returns the component with the most specific path containing path
+func componentForPath(path string) (config.Component, bool) {
+	var owner config.Component
+	found := false
+	for _, component := range config.AppConfig().Components() {
+		if component.Owns(path) && (!found || len(component.Path) > len(owner.Path)) {
+			owner, found = component, true
+		}
+	}
+	return owner, found
+}
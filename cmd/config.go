@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
+	"sykesdev.ca/gog/internal/prompt"
+)
+
+type ConfigCommand struct {
+	fs *flag.FlagSet
+
+	name string
+	action string
+	args []string
+	scope string
+	origin bool
+}
+
+type configValue struct {
+	Key string `json:"key"`
+	Value interface{} `json:"value,omitempty"`
+	Scope string `json:"scope,omitempty"`
+	Path string `json:"path,omitempty"`
+	Origin *config.Origin `json:"origin,omitempty"`
+}
+
+type configValidation struct {
+	Valid bool `json:"valid"`
+	Problems []string `json:"problems"`
+}
+
+func NewConfigCommand() *ConfigCommand {
+	cc := &ConfigCommand{
+		name: "config",
+		fs: flag.NewFlagSet("config", flag.ContinueOnError),
+	}
+
+	cc.fs.StringVar(&cc.scope, "scope", "", "specifies the config file to read or write: 'user' (global config) or 'repo' (.gog.yml at the project root). writes default to user")
+	cc.fs.BoolVar(&cc.origin, "origin", false, "shows where each effective value came from (default, user, repo or env)")
+
+	cc.fs.Usage = cc.Help
+
+	return cc
+}
+
+func (cc *ConfigCommand) Help() {
+	fmt.Printf(
+`Usage: %s %s (get <key> | set <key> <value> | unset <key> | list | show | edit | validate) [-scope user|repo] [-origin] [-h] [-help]
+
+-------====== Config Arguments ======-------
+
+get <key>
+	prints the effective value of a key (eg. application.tag_prefix), or its value in a single scope with -scope
+set <key> <value>
+	validates and saves a value in the user or repo config. lists may be given as 'a,b' or '[a, b]'
+unset <key>
+	removes a value from the user or repo config
+list
+	lists the effective configuration, or the values in a single scope with -scope
+show
+	prints the effective GOG configuration. values are layered as env (GOG_*) > repo (%s) > user > defaults
+edit
+	opens the user or repo config in $VISUAL or $EDITOR and validates it once closed
+validate
+	checks the user and repo config (or a single scope) for unknown keys and invalid values
+
+------================================------
+
+`, os.Args[0], cc.name, config.RepoConfigFile)
+
+	cc.fs.PrintDefaults()
+
+	fmt.Println("\n-------================================-------")
+}
+
+func (cc *ConfigCommand) Init(args []string) error {
+	if len(args) < 1 {
+		return errors.New("invalid usage of config command. must pass an action (re-run with -h for full usage details)")
+	}
+
+	cc.action = args[0]
+
+	// allow flags both before and after positional arguments
+	rest := args[1:]
+	for {
+		if err := cc.fs.Parse(rest); err != nil {
+			return err
+		}
+		if cc.fs.NArg() == 0 {
+			break
+		}
+		cc.args = append(cc.args, cc.fs.Arg(0))
+		rest = cc.fs.Args()[1:]
+	}
+
+	expected := map[string]int{"get": 1, "set": 2, "unset": 1}
+	if n, ok := expected[cc.action]; ok && len(cc.args) != n {
+		return fmt.Errorf("invalid usage of config %s. expected %d argument(s) but got %d (re-run with -h for full usage details)", cc.action, n, len(cc.args))
+	}
+
+	if cc.scope != "" && cc.scope != config.ScopeUser && cc.scope != config.ScopeRepo {
+		return fmt.Errorf("unknown config scope '%s'. must be one of %s, %s", cc.scope, config.ScopeUser, config.ScopeRepo)
+	}
+
+	return nil
+}
+
+func (cc *ConfigCommand) Run() (interface{}, error) {
+	// validate, edit and the actions on a single scope work on the config files themselves, so that a broken file can be repaired
+	if cc.effective() {
+		if err := config.Load(); err != nil {
+			return nil, output.Errorf(output.CodeConfig, "failed to load configuration. %v (run 'gog config validate' for details)", err)
+		}
+	}
+
+	switch cc.action {
+	case "get":
+		return cc.get(cc.args[0])
+	case "set":
+		return cc.set(cc.args[0], cc.args[1])
+	case "unset":
+		return cc.unset(cc.args[0])
+	case "list":
+		if cc.scope == "" {
+			return cc.show()
+		}
+		return cc.list()
+	case "show":
+		return cc.show()
+	case "edit":
+		return cc.edit()
+	case "validate":
+		return cc.validate()
+	default:
+		return nil, output.Errorf(output.CodeUsage, "unknown config action: %s (re-run with -h for full usage details)", cc.action)
+	}
+}
+
+// effective reports if the action reads the merged configuration rather than a single config file
+func (cc *ConfigCommand) effective() bool {
+	switch cc.action {
+	case "show":
+		return true
+	case "get", "list":
+		return cc.scope == ""
+	default:
+		return false
+	}
+}
+
+func (cc *ConfigCommand) show() (interface{}, error) {
+	settings := config.AppConfig().Settings()
+
+	for i, s := range settings {
+		s.Value = config.Redact(s.Key, s.Value)
+		settings[i] = s
+
+		if cc.origin {
+			fmt.Fprintf(output.Stdout(), "%s = %v\t(%s)\n", s.Key, s.Value, s.Origin)
+			continue
+		}
+		fmt.Fprintf(output.Stdout(), "%s = %v\n", s.Key, s.Value)
+	}
+
+	return settings, nil
+}
+
+func (cc *ConfigCommand) writeScope() string {
+	if cc.scope == "" {
+		return config.ScopeUser
+	}
+	return cc.scope
+}
+
+func (cc *ConfigCommand) get(key string) (interface{}, error) {
+	if cc.scope != "" {
+		d, err := config.LoadScope(cc.scope)
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := d.Get(key)
+		if !ok {
+			return nil, output.Errorf(output.CodeConfig, "%s is not set in %s config (%s)", key, cc.scope, d.Path)
+		}
+		value = config.Redact(key, value)
+		fmt.Fprintf(output.Stdout(), "%v\n", value)
+		return &configValue{Key: key, Value: value, Scope: cc.scope, Path: d.Path}, nil
+	}
+
+	for _, s := range config.AppConfig().Settings() {
+		if s.Key == key {
+			origin := s.Origin
+			s.Value = config.Redact(key, s.Value)
+			if cc.origin {
+				fmt.Fprintf(output.Stdout(), "%v\t(%s)\n", s.Value, s.Origin)
+			} else {
+				fmt.Fprintf(output.Stdout(), "%v\n", s.Value)
+			}
+			return &configValue{Key: key, Value: s.Value, Origin: &origin}, nil
+		}
+	}
+
+	return nil, output.Errorf(output.CodeConfig, "%s is not set", key)
+}
+
+func (cc *ConfigCommand) set(key, raw string) (interface{}, error) {
+	value, err := config.ParseValue(key, raw)
+	if err != nil {
+		return nil, output.WithCode(output.CodeConfig, err)
+	}
+
+	if cc.writeScope() == config.ScopeRepo && config.IsSecret(key) {
+		return nil, output.Errorf(output.CodeConfig, "%s is a secret and cannot be saved in the committed %s. use the user config or %s instead", key, config.RepoConfigFile, config.EnvName(key))
+	}
+	if cc.writeScope() == config.ScopeRepo && config.UserOnly(key) {
+		return nil, output.Errorf(output.CodeConfig, "%s cannot be set in the committed %s. use the user config or %s instead", key, config.RepoConfigFile, config.EnvName(key))
+	}
+
+	d, err := config.LoadScope(cc.writeScope())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.Set(key, value); err != nil {
+		return nil, output.WithCode(output.CodeConfig, err)
+	}
+
+	if err := d.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save %s config. %v", cc.writeScope(), err)
+	}
+
+	value = config.Redact(key, value)
+	logging.Instance().Infof("Set %s = %v in %s config (%s)", key, value, cc.writeScope(), d.Path)
+	return &configValue{Key: key, Value: value, Scope: cc.writeScope(), Path: d.Path}, nil
+}
+
+func (cc *ConfigCommand) unset(key string) (interface{}, error) {
+	d, err := config.LoadScope(cc.writeScope())
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.Unset(key) {
+		return nil, output.Errorf(output.CodeConfig, "%s is not set in %s config (%s)", key, cc.writeScope(), d.Path)
+	}
+
+	if err := d.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save %s config. %v", cc.writeScope(), err)
+	}
+
+	logging.Instance().Infof("Unset %s in %s config (%s)", key, cc.writeScope(), d.Path)
+	return &configValue{Key: key, Scope: cc.writeScope(), Path: d.Path}, nil
+}
+
+func (cc *ConfigCommand) list() (interface{}, error) {
+	d, err := config.LoadScope(cc.scope)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := d.Values()
+	if err != nil {
+		return nil, output.WithCode(output.CodeConfig, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []configValue
+	for _, key := range keys {
+		value := config.Redact(key, values[key])
+		fmt.Fprintf(output.Stdout(), "%s = %v\n", key, value)
+		result = append(result, configValue{Key: key, Value: value, Scope: cc.scope, Path: d.Path})
+	}
+
+	return result, nil
+}
+
+func (cc *ConfigCommand) edit() (interface{}, error) {
+	if !prompt.Interactive() {
+		return nil, output.Errorf(output.CodeInputRequired, "cannot open an editor in non-interactive mode. use 'gog config set' instead")
+	}
+
+	// the file is opened without parsing it, since it may be broken
+	path, err := config.ScopePath(cc.writeScope())
+	if err != nil {
+		return nil, err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	if !common.PathExists(path) {
+		d, err := config.LoadDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.Save(); err != nil {
+			return nil, fmt.Errorf("failed to create %s config. %v", cc.writeScope(), err)
+		}
+	}
+
+	editorArgs := strings.Fields(editor)
+	cmd := exec.Command(editorArgs[0], append(editorArgs[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run editor '%s'. %v", editor, err)
+	}
+
+	result := &configValidation{Valid: true, Problems: []string{}}
+	return result, cc.validateScope(cc.writeScope(), result)
+}
+
+// validateScope checks a single config file. a file which cannot be parsed is reported as a problem like any invalid value
+func (cc *ConfigCommand) validateScope(scope string, result *configValidation) error {
+	path, err := config.ScopePath(scope)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if d, err := config.LoadDocument(path); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = d.Validate()
+		if scope == config.ScopeRepo {
+			errs = append(errs, repoProblems(d)...)
+		}
+	}
+
+	for _, err := range errs {
+		logging.Instance().Errorf("%s config (%s): %v", scope, path, err)
+		result.Problems = append(result.Problems, fmt.Sprintf("%s config (%s): %v", scope, path, err))
+	}
+
+	if len(errs) > 0 {
+		result.Valid = false
+		return output.Errorf(output.CodeConfig, "%s config (%s) has %d problem(s)", scope, path, len(errs))
+	}
+
+	logging.Instance().Infof("%s config (%s) is valid", scope, path)
+	return nil
+}
+
+// repoProblems reports the keys set in the repo config, which is committed with the project, that belong in the user config
+func repoProblems(d *config.Document) []error {
+	values, err := d.Values()
+	if err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		switch {
+		case config.IsSecret(key):
+			errs = append(errs, fmt.Errorf("%s is a secret and should not be committed. move it to the user config or %s", key, config.EnvName(key)))
+		case config.UserOnly(key):
+			errs = append(errs, fmt.Errorf("%s is ignored in the repo config. move it to the user config or %s", key, config.EnvName(key)))
+		}
+	}
+	return errs
+}
+
+func (cc *ConfigCommand) validate() (interface{}, error) {
+	result := &configValidation{Valid: true, Problems: []string{}}
+
+	if cc.scope != "" {
+		return result, cc.validateScope(cc.scope, result)
+	}
+
+	for _, scope := range []string{config.ScopeUser, config.ScopeRepo} {
+		path, err := config.ScopePath(scope)
+		if err != nil || !common.PathExists(path) {
+			continue
+		}
+		cc.validateScope(scope, result)
+	}
+
+	for _, err := range config.ValidateEnv() {
+		logging.Instance().Errorf("environment: %v", err)
+		result.Problems = append(result.Problems, fmt.Sprintf("environment: %v", err))
+		result.Valid = false
+	}
+
+	// settings which depend on several files (eg. a tracker defined in the user config and selected in the repo config) can only be checked once each file is valid
+	if result.Valid && config.Load() == nil {
+		for _, err := range config.AppConfig().Validate() {
+			logging.Instance().Errorf("effective config: %v", err)
+			result.Problems = append(result.Problems, fmt.Sprintf("effective config: %v", err))
+			result.Valid = false
+		}
+	}
+
+	if !result.Valid {
+		return result, output.WithCode(output.CodeConfig, errors.New("configuration is invalid"))
+	}
+	return result, nil
+}
+
+func (cc *ConfigCommand) Name() string {
+	return cc.name
+}
+
+func (cc *ConfigCommand) Alias() string {
+	return ""
+}
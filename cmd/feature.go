@@ -5,18 +5,20 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
 
 	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/git"
 	"sykesdev.ca/gog/internal/logging"
 	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/output"
 	"sykesdev.ca/gog/internal/prompt"
+	"sykesdev.ca/gog/internal/tickets"
 )
 
 func FeatureUsage() {
-	logging.Instance().Info("Usage: gog feature <jira_name> <comment> [-from-feature]")
+	logging.Instance().Info("Usage: gog feature <ticket> <comment> [-from-feature]")
 }
 
 type FeatureCommand struct {
@@ -24,12 +26,23 @@ type FeatureCommand struct {
 	
 	name string
 	alias string
-	Jira string
+	Ticket string
 	Comment string
 	CustomVersionPrefix string
+	Component string
 	FromFeature bool
 }
 
+type featureResult struct {
+	Ticket string `json:"ticket"`
+	Comment string `json:"comment"`
+	Branch string `json:"branch"`
+	VersionPrefix string `json:"version_prefix"`
+	Component string `json:"component,omitempty"`
+	ParentFeature string `json:"parent_feature,omitempty"`
+	ParentCommit string `json:"parent_commit,omitempty"`
+}
+
 func NewFeatureCommand() *FeatureCommand {
 	fc := &FeatureCommand{
 		name: "feature",
@@ -38,7 +51,8 @@ func NewFeatureCommand() *FeatureCommand {
 	}
 
 	fc.fs.StringVar(&fc.CustomVersionPrefix, "prefix", "", "optionally specifies a version prefix to use for this feature which will override existing prefix in global GOG config")
-	fc.fs.BoolVar(&fc.FromFeature, "from-feature", false, "specifies if this feature will be based on the a current feature branch")
+	fc.fs.StringVar(&fc.Component, "component", "", "specifies the monorepo component (see components in config) this feature is versioned and released with. inferred from the changed files on finish when omitted")
+	fc.fs.BoolVar(&fc.FromFeature, "from-feature", false, "specifies that this feature will be stacked on the current feature branch instead of the default branch")
 
 	fc.fs.Usage = fc.Help
 
@@ -47,12 +61,12 @@ func NewFeatureCommand() *FeatureCommand {
 
 func (fc *FeatureCommand) Help() {
 	fmt.Printf(
-`Usage: %s (%s | %s) <jira> <comment> [-from-feature] [-h] [-help]
+`Usage: %s (%s | %s) [-prefix prefix | -component name] [-from-feature] <ticket> <comment> [-h] [-help]
 
 -------====== Feature Arguments ======-------
 
-jira
-	specifies the JIRA issue we are working under
+ticket
+	specifies the ticket we are working under (eg. 'ABC-123'), validated against the configured ticket tracker
 comment
 	specifies a human-readable comment describing the issue/feature
 
@@ -69,23 +83,28 @@ func (fc *FeatureCommand) Init(args []string) error {
 	err := fc.fs.Parse(args)
 
 	if len(fc.fs.Args()) < 2 {
-		return errors.New("invalid usage of feature command. must pass a jira identifier and comment (re-run with -h for full usage details)")
+		return errors.New("invalid usage of feature command. must pass a ticket and comment (re-run with -h for full usage details)")
 	}
 
-	fc.Jira = fc.fs.Arg(0)
+	fc.Ticket = fc.fs.Arg(0)
 	fc.Comment = strings.Join(fc.fs.Args()[1:], " ")
 
 	return err
 }
 
-func (fc *FeatureCommand) Run() error {
-	validJiraFormat, err := regexp.Match(`^([A-Za-z])+-([0-9])+$`, []byte(fc.Jira))
-	if err != nil {
-		return fmt.Errorf("failed to parse regular expression for Jira format. %v", err)
+func (fc *FeatureCommand) Run() (interface{}, error) {
+	if err := tickets.Validate(fc.Ticket); err != nil {
+		return nil, output.WithCode(output.CodeInvalidTicket, err)
 	}
 
-	if !validJiraFormat {
-		return errors.New("invalid Jira format ... example of a valid format would be 'JIRA-0023'")
+	if fc.Component != "" && fc.CustomVersionPrefix != "" {
+		return nil, output.Errorf(output.CodeUsage, "cannot use -prefix with -component since components are tagged with their own tag_pattern")
+	}
+
+	if fc.Component != "" {
+		if err := config.AppConfig().SetComponent(fc.Component); err != nil {
+			return nil, output.WithCode(output.CodeConfig, err)
+		}
 	}
 
 	if fc.CustomVersionPrefix != config.AppConfig().TagPrefix() && fc.CustomVersionPrefix != "" {
@@ -95,61 +114,104 @@ func (fc *FeatureCommand) Run() error {
 
 	r, err := git.NewRepository()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	feature, err := models.NewFeature(fc.Jira, fc.Comment, fc.CustomVersionPrefix)
+	feature, err := models.NewFeature(fc.Ticket, fc.Comment, fc.CustomVersionPrefix)
 	if err != nil {
-		return fmt.Errorf("failed to create feature object. %v", err)
+		return nil, output.Errorf(output.CodeUsage, "failed to create feature object. %v", err)
 	}
+	feature.Component = fc.Component
 
 	if r.VersionPrefix != config.AppConfig().TagPrefix() {
 		logging.Instance().Warnf("feature version prefix specified does not match existing prefix for this git project ('%s' != '%s')", config.AppConfig().TagPrefix(), r.VersionPrefix)
-		if c := prompt.String("continue with feature creation (Y/n)? "); strings.ToUpper(c) != "Y" {
+		proceed, err := prompt.Confirm("continue with feature creation", prompt.NoDefault)
+		if err != nil {
+			return nil, err
+		}
+		if !proceed {
 			logging.Instance().Info("safely exiting feature creation")
 			logging.Instance().Info("if you wish to use the existing version prefix, but it is not set in the global config for GOG, you can pass it using the -prefix flag (see -help for details)")
-			return nil
+			return nil, nil
 		}
 		logging.Instance().Info("continuing with feature creation against warning")
 	}
 
-	if r.ContainsBranch(feature.Jira) {
-		return fmt.Errorf("there is already a branch in this repo named %s", feature.Jira)
+	if r.ContainsBranch(feature.Ticket) {
+		return nil, output.Errorf(output.CodeBranchExists, "there is already a branch in this repo named %s", feature.Ticket)
 	}
 
+	var parent *models.Feature
 	if fc.FromFeature {
-		return errors.New("from-feature not implemented yet")
+		if r.CurrentBranch.Name == r.DefaultBranch.Name || !common.PathExists(common.GOGPath() + "/feature.json") {
+			return nil, output.Errorf(output.CodeNoFeature, "cannot create a feature from the current branch (%s) since it is not a GOG feature branch", r.CurrentBranch)
+		}
+
+		parent, err = models.NewFeatureFromFile()
+		if err != nil {
+			return nil, output.Errorf(output.CodeNoFeature, "failed to read parent feature from associated feature file. %v", err)
+		}
 	}
 
 	if err := r.StageChanges(); err != nil {
-		return fmt.Errorf("failed to stage changes on current branch (%s) before starting new feature. %v", r.CurrentBranch, err)
+		return nil, output.Errorf(output.CodeGit, "failed to stage changes on current branch (%s) before starting new feature. %v", r.CurrentBranch, err)
 	}
 
 	if r.CurrentBranch.UncommittedChanges() && r.CurrentBranch.Name != r.DefaultBranch.Name {
-		return fmt.Errorf("the current branch (%s) has uncommitted changes, please review and discard/commit them before starting a new feature", r.CurrentBranch)
+		return nil, output.Errorf(output.CodeUncommittedChanges, "the current branch (%s) has uncommitted changes, please review and discard/commit them before starting a new feature", r.CurrentBranch)
 	}
 
-	if err := r.CheckoutBranch(r.DefaultBranch, false, false); err != nil {
-		return fmt.Errorf("failed to checkout branch %s. %v", r.DefaultBranch, err)
-	}
+	if parent != nil {
+		if r.CurrentBranch.RemoteExists {
+			if err := r.PullChanges(); err != nil {
+				return nil, output.Errorf(output.CodeGit, "failed to ensure parent feature %s is up to date with remote. %v", r.CurrentBranch, err)
+			}
+		}
+
+		parentCommit, err := r.HeadCommit()
+		if err != nil {
+			return nil, output.Errorf(output.CodeGit, "failed to capture the current commit for parent feature %s. %v", parent.Ticket, err)
+		}
+
+		feature.SetParent(parent, parentCommit)
+	} else {
+		if err := r.CheckoutBranch(r.DefaultBranch, false, false); err != nil {
+			return nil, output.Errorf(output.CodeGit, "failed to checkout branch %s. %v", r.DefaultBranch, err)
+		}
 
-	if err := r.PullChanges(); err != nil {
-		return fmt.Errorf("failed to pull some changes before creating the new feature. %v", err)
+		if err := r.PullChanges(); err != nil {
+			return nil, output.Errorf(output.CodeGit, "failed to pull some changes before creating the new feature. %v", err)
+		}
 	}
 
-	r.FeatureBranch = git.NewBranch(feature.Jira)
+	r.FeatureBranch = r.NewBranch(feature.Ticket)
 
 	if err := r.CheckoutBranch(r.FeatureBranch, true, true); err != nil {
-		return fmt.Errorf("failed to create or checkout new feature branch, %s. %v", feature.Jira, err)
+		return nil, output.Errorf(output.CodeGit, "failed to create or checkout new feature branch, %s. %v", feature.Ticket, err)
 	}
 
 	if err := feature.Save(); err != nil {
-		return fmt.Errorf("failed to create feature tracking file (%v)", err)
+		return nil, fmt.Errorf("failed to create feature tracking file (%v)", err)
+	}
+
+	result := &featureResult{
+		Ticket: feature.Ticket,
+		Comment: feature.Comment,
+		Branch: r.FeatureBranch.Name,
+		VersionPrefix: config.AppConfig().TagPrefix(),
+		Component: feature.Component,
+		ParentFeature: feature.ParentFeature,
+		ParentCommit: feature.ParentCommit,
+	}
+
+	if feature.IsStacked() {
+		logging.Instance().Infof("Successfully created feature %s on top of %s!", feature.Ticket, feature.ParentFeature)
+		return result, nil
 	}
 
-	logging.Instance().Infof("Successfully created feature %s!", feature.Jira)
+	logging.Instance().Infof("Successfully created feature %s!", feature.Ticket)
 
-	return nil
+	return result, nil
 }
 
 func (fc *FeatureCommand) Name() string {
@@ -10,28 +10,73 @@ import (
 	"sykesdev.ca/gog/config"
 	"sykesdev.ca/gog/internal/changelog"
 	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/conventional"
+	"sykesdev.ca/gog/internal/forge"
 	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/journal"
 	"sykesdev.ca/gog/internal/logging"
 	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/output"
 	"sykesdev.ca/gog/internal/prompt"
 	"sykesdev.ca/gog/internal/semver"
 )
 
 type FinishAction string
 
-func bumpReleaseVersion(currentVersion semver.Semver, action FinishAction) (semver.Semver) {
+// releaseSatisfied reports if a pre-release version already carries the given bump, eg. 1.3.0-rc.1 for a MINOR release after 1.2.x
+func releaseSatisfied(currentVersion semver.Semver, action FinishAction) bool {
+	if !currentVersion.IsPreRelease() {
+		return false
+	}
+
 	switch action {
 	case "MAJOR":
-		return currentVersion.BumpMajor()
+		return currentVersion.Core[1] == 0 && currentVersion.Core[2] == 0
 	case "MINOR":
-		return currentVersion.BumpMinor()
-	case "PATCH":
-		return currentVersion.BumpPatch()
+		return currentVersion.Core[2] == 0
 	default:
-		return currentVersion
+		return true
 	}
 }
 
+func bumpReleaseVersion(currentVersion semver.Semver, action FinishAction, preRelease string) (semver.Semver) {
+	next := currentVersion.Release()
+
+	if !releaseSatisfied(currentVersion, action) {
+		switch action {
+		case "MAJOR":
+			next = currentVersion.BumpMajor()
+		case "MINOR":
+			next = currentVersion.BumpMinor()
+		case "PATCH":
+			next = currentVersion.BumpPatch()
+		default:
+			return currentVersion
+		}
+	}
+
+	if preRelease == "" {
+		return next
+	}
+
+	if next.Equal(currentVersion.Release()) {
+		return currentVersion.BumpPreRelease(preRelease)
+	}
+
+	return next.BumpPreRelease(preRelease)
+}
+
+// nextReleaseVersion is bumpReleaseVersion, refusing a pre-release which would sort below the current version, eg. v1.3.0-beta.1 after v1.3.0-rc.1
+func nextReleaseVersion(currentVersion semver.Semver, action FinishAction, preRelease string) (semver.Semver, error) {
+	next := bumpReleaseVersion(currentVersion, action, preRelease)
+
+	if preRelease != "" && currentVersion.IsPreRelease() && !next.GreaterThan(currentVersion) {
+		return next, output.Errorf(output.CodeUsage, "cannot release %s after %s since pre-release '%s' has a lower precedence than '%s'. continue with -pre %s, or release %s without -pre first", next, currentVersion, preRelease, currentVersion.PreReleaseID(), currentVersion.PreReleaseID(), currentVersion.Release())
+	}
+
+	return next, nil
+}
+
 type FinishCommand struct {
 	fs *flag.FlagSet
 
@@ -42,9 +87,45 @@ type FinishCommand struct {
 	major bool
 	minor bool
 	patch bool
+	auto bool
 
 	noChangelog bool
 	noTag bool
+	preRelease string
+	component string
+	pullRequest bool
+	publish bool
+	sign bool
+
+	resume bool
+	abort bool
+	dryRun bool
+
+	plan *releasePlan
+	forge forge.Forge
+
+	pullRequestOpened *forge.PullRequest
+	releasePublished *forge.Release
+}
+
+type finishResult struct {
+	Ticket string `json:"ticket"`
+	Component string `json:"component,omitempty"`
+	Action FinishAction `json:"action"`
+	Version string `json:"version"`
+	PreviousVersion string `json:"previous_version,omitempty"`
+	Tags []string `json:"tags"`
+	Commit string `json:"commit,omitempty"`
+	Branch string `json:"branch"`
+	FeatureBranch string `json:"feature_branch"`
+	PullRequest *forge.PullRequest `json:"pull_request,omitempty"`
+	Release *forge.Release `json:"release,omitempty"`
+
+	DryRun bool `json:"dry_run,omitempty"`
+	Changelog string `json:"changelog,omitempty"`
+	VersionFiles string `json:"version_files_diff,omitempty"`
+	Files []string `json:"files,omitempty"`
+	Operations []string `json:"operations,omitempty"`
 }
 
 func NewFinishCommand() *FinishCommand {
@@ -57,8 +138,17 @@ func NewFinishCommand() *FinishCommand {
 	fc.fs.BoolVar(&fc.major, "major", false, "specifies that in this freature you make incompatible API changes (breaking changes)")
 	fc.fs.BoolVar(&fc.minor, "minor", false, "specifies that in this feature you add functionality in a backwards compatible manner (non-breaking)")
 	fc.fs.BoolVar(&fc.patch, "patch", false, "specifies that in this feature you make backwards compatible bug fixes small backwards compatible updates")
+	fc.fs.BoolVar(&fc.auto, "auto", false, "infers major, minor or patch from the conventional commit messages on this feature branch (explicit flags take precedence)")
+	fc.fs.StringVar(&fc.preRelease, "pre", "", "creates a pre-release with the given identifier (eg. 'rc' for v1.3.0-rc.1). finishing without -pre promotes the latest pre-release to its final version")
 	fc.fs.BoolVar(&fc.noChangelog, "no-changelog", false, "if this flag is set, no changelog creation or updates shall be performed when finishing this feature release")
 	fc.fs.BoolVar(&fc.noTag, "no-tag", false, "if this flag is set, no version tagging shall be applied to this finished feature release")	
+	fc.fs.StringVar(&fc.component, "component", "", "releases the named component of a monorepo (see components in config). defaults to the component of the feature, or the one owning the changed files")
+	fc.fs.BoolVar(&fc.publish, "publish", false, "publishes a release on the forge for the new tag with the changelog entry as its notes and any release.assets attached. enabled by default with release.publish in config")
+	fc.fs.BoolVar(&fc.sign, "sign", false, "signs the release tags with GPG or SSH (see release.signing_format and release.signing_key in config). enabled by default with release.sign in config")
+	fc.fs.BoolVar(&fc.pullRequest, "pr", false, "pushes the rebased feature branch and opens a pull request instead of merging locally. tagging and the changelog are deferred to 'gog release' once the pull request is merged")
+	fc.fs.BoolVar(&fc.resume, "continue", false, "continues a feature release which was paused by rebase or merge conflicts once they have been resolved")
+	fc.fs.BoolVar(&fc.dryRun, "dry-run", false, "prints the full release plan (version, changelog entry, commit, tags and branches) without changing the repository or remote")
+	fc.fs.BoolVar(&fc.abort, "abort", false, "aborts a feature release which was paused by rebase or merge conflicts and restores the state from before finish")
 
 	fc.fs.Usage = fc.Help
 
@@ -67,7 +157,7 @@ func NewFinishCommand() *FinishCommand {
 
 func (fc *FinishCommand) Help() {
 	fmt.Printf(
-`Usage: %s (%s | %s) (-major | -minor | -patch) [ additional_options... ] [-h] [-help]
+`Usage: %s (%s | %s) (-major | -minor | -patch | -auto | -continue | -abort) [-pr] [ additional_options... ] [-h] [-help]
 
 -------====== Finish Arguments ======-------
 
@@ -81,6 +171,14 @@ func (fc *FinishCommand) Help() {
 func (fc *FinishCommand) Init(args []string) error {
 	err := fc.fs.Parse(args)
 
+	if fc.resume && fc.abort {
+		return errors.New("cannot specify both -continue and -abort for the same feature release")
+	}
+
+	if fc.resume || fc.abort {
+		return err
+	}
+
 	if fc.major {
 		fc.action = "MAJOR"
 	} else if fc.minor {
@@ -89,23 +187,168 @@ func (fc *FinishCommand) Init(args []string) error {
 		fc.action = "PATCH"
 	}
 
-	if fc.action == "" {
-		return errors.New("failed to specify major, minor or patch for this feature upgrade (re-run wiht -h for full usage details)")
+	if fc.publish && fc.noTag {
+		return errors.New("cannot publish a release for a feature finished with -no-tag")
+	}
+
+	if fc.sign && fc.noTag {
+		return errors.New("cannot sign the release tags of a feature finished with -no-tag")
+	}
+
+	if fc.preRelease != "" && !semver.ValidPreReleaseID(fc.preRelease) {
+		return fmt.Errorf("invalid pre-release identifier '%s'. must be a single identifier of alphanumerics and hyphens (eg. 'rc'), without dots or leading zeros", fc.preRelease)
+	}
+
+	if fc.action != "" && fc.auto {
+		logging.Instance().Debugf("explicit release action %s overrides -auto", fc.action)
+		fc.auto = false
+	}
+
+	if fc.action == "" && !fc.auto {
+		return errors.New("failed to specify major, minor, patch or auto for this feature upgrade (re-run wiht -h for full usage details)")
 	}
 
 	return err
 }
 
-func (fc *FinishCommand) Run() error {
+func (fc *FinishCommand) Run() (interface{}, error) {
+	if fc.resume {
+		return fc.continueFinish()
+	}
+
+	if fc.abort {
+		return fc.abortFinish()
+	}
+
+	if finishInProgress(git.DefaultBackend()) {
+		return nil, output.Errorf(output.CodeInProgress, "a feature release is already in progress for this repository. resolve any conflicts and run 'gog finish -continue', or run 'gog finish -abort' to cancel it")
+	}
+
+	if releaseInProgress(git.DefaultBackend()) {
+		return nil, output.Errorf(output.CodeInProgress, "a release is already in progress for this repository. run 'gog release -continue' or 'gog release -abort' first")
+	}
+
+	feature, err := loadReleaseFeature(fc.component)
+	if err != nil {
+		return nil, err
+	}
+
+	var r *git.Repository
+	if fc.dryRun {
+		fc.plan = &releasePlan{recorder: git.NewRecordingBackend(git.DefaultBackend())}
+		r, err = git.NewRepositoryWithBackend(fc.plan.recorder)
+	} else {
+		r, err = git.NewRepository()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	*r.FeatureBranch = *r.CurrentBranch
+
+	if r.VersionPrefix != config.AppConfig().TagPrefix() {
+		logging.Instance().Warnf("feature version prefix specified does not match existing prefix for this git project ('%s' != '%s')", config.AppConfig().TagPrefix(), r.VersionPrefix)
+		proceed, err := prompt.Confirm("continue with feature release", prompt.NoDefault)
+		if err != nil {
+			return nil, err
+		}
+		if !proceed {
+			logging.Instance().Info("safely exiting feature release")
+			return nil, nil
+		}
+		logging.Instance().Info("continuing with feature release against warning")
+	}
+
+	if err := r.PullChanges(); err != nil {
+		return nil, output.Errorf(output.CodeGit, "failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
+	}
+
+	if feature.IsStacked() && r.ContainsBranch(feature.ParentFeature) {
+		return nil, output.Errorf(output.CodeInProgress, "cannot finish %s since its parent feature (%s) has not been merged yet. finish %s first and try again", feature.Ticket, feature.ParentFeature, feature.ParentFeature)
+	}
+
+	if fc.auto {
+		action, err := fc.inferAction(r, feature)
+		if err != nil {
+			return nil, err
+		}
+
+		if action == "" {
+			logging.Instance().Info("safely exiting feature release")
+			return nil, nil
+		}
+
+		fc.action = action
+	}
+
+	updatedVersion, err := nextReleaseVersion(r.LastTag, fc.action, fc.preRelease)
+	if err != nil {
+		return nil, err
+	}
+
+	fc.publish = (fc.publish || config.AppConfig().PublishRelease()) && !fc.noTag
+	fc.sign = (fc.sign || config.AppConfig().SignTags()) && !fc.noTag
+
+	if fc.dryRun {
+		return fc.printPlan(r, &finishState{Action: fc.action, Version: updatedVersion, Feature: feature, Journal: &journal.Journal{}})
+	}
+
+	if fc.pullRequest || fc.publish {
+		if fc.forge, err = openForge(r); err != nil {
+			return nil, err
+		}
+	}
+
+	j, err := journal.New(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start finish journal. %v", err)
+	}
+
+	for _, path := range []string{".gog", changelog.File()} {
+		if err := j.Backup(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := backupVersionFiles(j); err != nil {
+		return nil, err
+	}
+
+	state := &finishState{
+		Action: fc.action,
+		Version: updatedVersion,
+		NoChangelog: fc.noChangelog,
+		NoTag: fc.noTag,
+		PreRelease: fc.preRelease,
+		PullRequest: fc.pullRequest,
+		Publish: fc.publish,
+		Sign: fc.sign,
+		Feature: feature,
+		Journal: j,
+	}
+
+	if err := state.save(r.Backend()); err != nil {
+		return nil, fmt.Errorf("failed to save finish progress. %v", err)
+	}
+
+	result, err := fc.runSteps(r, state)
+	if result != nil {
+		result.PreviousVersion = r.LastTag.String()
+	}
+	return result, err
+}
+
+// loadReleaseFeature reads the feature of the current branch and selects the tag prefix and component it is released with
+func loadReleaseFeature(component string) (*models.Feature, error) {
 	GOGDir := common.GOGPath()
 
 	if !common.PathExists(GOGDir + "/feature.json") {
-		return errors.New("feature file not found ... there may not be a GOG feature on this branch")
+		return nil, output.Errorf(output.CodeNoFeature, "feature file not found ... there may not be a GOG feature on this branch")
 	}
 
 	feature, err := models.NewFeatureFromFile()
 	if err != nil {
-		return fmt.Errorf("failed to read feature from associated feature file. %v", err)
+		return nil, output.Errorf(output.CodeNoFeature, "failed to read feature from associated feature file. %v", err)
 	}
 
 	if feature.CustomVersionPrefix != config.AppConfig().TagPrefix() && feature.CustomVersionPrefix != "" {
@@ -113,97 +356,727 @@ func (fc *FinishCommand) Run() error {
 		config.AppConfig().SetTagPrefix(feature.CustomVersionPrefix)
 	}
 
+	if component != "" {
+		feature.Component = component
+	}
+	if feature.Component, err = selectComponent(feature.Component); err != nil {
+		return nil, err
+	}
+
+	return feature, nil
+}
+
+// releaseEntry is the changelog entry finish writes for feature. unless noChangelog, it is added to the changelog (taking over the content of the
+// Unreleased block) and the updated changelog lines are returned
+func releaseEntry(r *git.Repository, feature *models.Feature, action FinishAction, version semver.Semver, noChangelog bool) (*changelog.ChangelogEntry, []string, error) {
+	entry := changelog.NewChangelogEntry(feature, r, version, action == "MAJOR" || action == "MINOR")
+	entry.Previous = r.LastTag
+
+	if noChangelog {
+		if _, err := entry.Build(); err != nil {
+			return nil, nil, output.Errorf(output.Code(err), "failed to build the changelog entry. %v", err)
+		}
+		return entry, nil, nil
+	}
+
+	lines, err := changelog.CreateChangeLogLines(entry)
+	if err != nil {
+		return nil, nil, output.Errorf(output.Code(err), "failed to update the changelog. %v", err)
+	}
+	return entry, lines, nil
+}
+
+// tagSigning is how release tags are signed, using the signing format and key from config
+func tagSigning(sign bool) git.Signing {
+	return git.Signing{Sign: sign, Format: config.AppConfig().SigningFormat(), Key: common.ExpandHome(config.AppConfig().SigningKey())}
+}
+
+func (fc *FinishCommand) runSteps(r *git.Repository, state *finishState) (*finishResult, error) {
+	j := state.Journal
+
+	for _, step := range fc.releaseSteps(r, state) {
+		if j.Done(step.name) {
+			logging.Instance().Debugf("skipping completed finish step: %s", step.name)
+			continue
+		}
+
+		logging.Instance().Debugf("running finish step: %s", step.name)
+
+		if err := step.run(); err != nil {
+			if r.RebaseInProgress() || len(r.Conflicts()) > 0 {
+				return nil, fc.pause(r, state, step.name, err)
+			}
+
+			if step.retry {
+				return nil, fc.retryLater(r, state, step.name, err)
+			}
+
+			if clearErr := clearFinishState(r.Backend()); clearErr != nil {
+				logging.Instance().Debugf("failed to clear finish progress. %v", clearErr)
+			}
+
+			return nil, rollback(r, j, err)
+		}
+
+		if step.remote != "" {
+			j.CompleteRemote(step.name, step.remote)
+		} else {
+			j.Complete(step.name)
+		}
+
+		if err := state.save(r.Backend()); err != nil {
+			return nil, fmt.Errorf("failed to save finish progress after %s. %v", step.name, err)
+		}
+	}
+
+	if err := clearFinishState(r.Backend()); err != nil {
+		return nil, fmt.Errorf("failed to clear finish progress. %v", err)
+	}
+
+	result := &finishResult{
+		Ticket: state.Feature.Ticket,
+		Component: state.Feature.Component,
+		Action: state.Action,
+		Version: state.Version.String(),
+		Tags: []string{},
+		Branch: r.DefaultBranch.Name,
+		FeatureBranch: state.Journal.FeatureBranch,
+		PullRequest: fc.pullRequestOpened,
+		Release: fc.releasePublished,
+	}
+
+	if state.PullRequest {
+		logging.Instance().Infof("Successfully opened a pull request for %s! run 'gog release' on %s once it has been merged", state.Feature.Ticket, r.DefaultBranch)
+		return result, nil
+	}
+
+	if !state.NoTag {
+		result.Tags = releaseTags(state.Version)
+	}
+
+	if commit, err := r.HeadCommit(); err == nil {
+		result.Commit = commit
+	}
+
+	logging.Instance().Infof("Successfully created new feature release for %s!", state.Feature.Ticket)
+
+	return result, nil
+}
+
+func (fc *FinishCommand) pause(r *git.Repository, state *finishState, step string, cause error) error {
+	state.Journal.Paused = step
+
+	if err := state.save(r.Backend()); err != nil {
+		logging.Instance().Errorf("failed to save finish progress, rolling back instead. %v", err)
+		return rollback(r, state.Journal, cause)
+	}
+
+	logging.Instance().Warnf("feature release for %s paused during '%s' due to conflicts", state.Feature.Ticket, step)
+	for _, f := range r.Conflicts() {
+		logging.Instance().Warnf("  - conflict: %s", f)
+	}
+	logging.Instance().Info("resolve the conflicts and stage them with 'git add', then run 'gog finish -continue'")
+	logging.Instance().Info("to cancel the release and restore the state from before finish, run 'gog finish -abort'")
+
+	return output.Errorf(output.CodeConflict, "feature release paused by conflicts. %v", cause)
+}
+
+// retryLater keeps the finish in progress after a step which is safe to rerun failed, so it can be retried with 'gog finish -continue'
+func (fc *FinishCommand) retryLater(r *git.Repository, state *finishState, step string, cause error) error {
+	if err := state.save(r.Backend()); err != nil {
+		logging.Instance().Errorf("failed to save finish progress, rolling back instead. %v", err)
+		return rollback(r, state.Journal, cause)
+	}
+
+	logging.Instance().Warnf("feature release for %s stopped during '%s'", state.Feature.Ticket, step)
+	logging.Instance().Info("fix the problem and run 'gog finish -continue' to retry the remaining steps")
+
+	return cause
+}
+
+func (fc *FinishCommand) loadPausedState() (*git.Repository, *finishState, error) {
+	if !finishInProgress(git.DefaultBackend()) {
+		return nil, nil, output.Errorf(output.CodeUsage, "there is no feature release in progress for this repository")
+	}
+
+	state, err := loadFinishState(git.DefaultBackend())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read finish progress. %v", err)
+	}
+
+	fc.action, fc.noChangelog, fc.noTag = state.Action, state.NoChangelog, state.NoTag
+	fc.preRelease, fc.pullRequest, fc.publish, fc.sign = state.PreRelease, state.PullRequest, state.Publish, state.Sign
+
+	if state.Feature.CustomVersionPrefix != config.AppConfig().TagPrefix() && state.Feature.CustomVersionPrefix != "" {
+		logging.Instance().Debugf("setting application preset for prefix: %s", state.Feature.CustomVersionPrefix)
+		config.AppConfig().SetTagPrefix(state.Feature.CustomVersionPrefix)
+	}
+
+	if err := config.AppConfig().SetComponent(state.Feature.Component); err != nil {
+		return nil, nil, output.WithCode(output.CodeConfig, err)
+	}
+
 	r, err := git.NewRepository()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	*r.FeatureBranch = *r.CurrentBranch
+	r.FeatureBranch = r.NewBranch(state.Journal.FeatureBranch)
 
-	if r.VersionPrefix != config.AppConfig().TagPrefix() {
-		logging.Instance().Warnf("feature version prefix specified does not match existing prefix for this git project ('%s' != '%s')", config.AppConfig().TagPrefix(), r.VersionPrefix)
-		if c := prompt.String("continue with feature release (Y/n)? "); strings.ToUpper(c) != "Y" {
-			logging.Instance().Info("safely exiting feature release")
-			return nil
+	if (fc.pullRequest || fc.publish) && !fc.abort {
+		if fc.forge, err = openForge(r); err != nil {
+			return nil, nil, err
 		}
-		logging.Instance().Info("continuing with feature release against warning")
 	}
 
-	if err := r.PullChanges(); err != nil {
-		return fmt.Errorf("failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
+	return r, state, nil
+}
+
+func (fc *FinishCommand) continueFinish() (*finishResult, error) {
+	r, state, err := fc.loadPausedState()
+	if err != nil {
+		return nil, err
 	}
 
-	updatedVersion := bumpReleaseVersion(r.LastTag, fc.action)
+	if conflicts := r.Conflicts(); len(conflicts) > 0 {
+		return nil, output.Errorf(output.CodeConflict, "there are still unresolved conflicts in: %s. resolve and stage them with 'git add' before continuing", strings.Join(conflicts, ", "))
+	}
 
-	if !fc.noChangelog && !fc.noTag {
-		changelogEntry := changelog.NewChangelogEntry(feature, r, updatedVersion, fc.action == "MAJOR" || fc.action == "MINOR")
-		changelogLines, err := changelog.CreateChangeLogLines(changelogEntry)
-		if err != nil {
-			return fmt.Errorf("failed to update the changelog. %v", err)
+	if r.RebaseInProgress() {
+		logging.Instance().Infof("continuing rebase for %s", state.Journal.FeatureBranch)
+
+		if err := r.ContinueRebase(); err != nil {
+			if r.RebaseInProgress() || len(r.Conflicts()) > 0 {
+				return nil, fc.pause(r, state, state.Journal.Paused, err)
+			}
+			return nil, output.Errorf(output.CodeGit, "failed to continue rebase. %v", err)
 		}
+	}
+
+	if err := r.RefreshCurrentBranch(); err != nil {
+		return nil, err
+	}
+
+	if state.Journal.Paused != "" {
+		state.Journal.Complete(state.Journal.Paused)
+		state.Journal.Paused = ""
+	}
+
+	logging.Instance().Infof("continuing feature release for %s (%s)", state.Feature.Ticket, state.Version)
+
+	return fc.runSteps(r, state)
+}
+
+func (fc *FinishCommand) abortFinish() (*finishResult, error) {
+	r, state, err := fc.loadPausedState()
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Instance().Infof("aborting feature release for %s", state.Feature.Ticket)
+
+	if err := state.Journal.Rollback(r); err != nil {
+		return nil, output.Errorf(output.CodeGit, "failed to restore the state from before finish, manual cleanup may be required. %v", err)
+	}
+
+	if err := clearFinishState(r.Backend()); err != nil {
+		return nil, fmt.Errorf("failed to clear finish progress. %v", err)
+	}
+
+	reportRemote(state.Journal)
+
+	logging.Instance().Infof("Successfully aborted feature release for %s!", state.Feature.Ticket)
+
+	return &finishResult{
+		Ticket: state.Feature.Ticket,
+		Component: state.Feature.Component,
+		Action: state.Action,
+		Version: state.Version.String(),
+		Tags: []string{},
+		Branch: r.DefaultBranch.Name,
+		FeatureBranch: state.Journal.FeatureBranch,
+	}, nil
+}
+
+func (fc *FinishCommand) inferAction(r *git.Repository, feature *models.Feature) (FinishAction, error) {
+	commits, err := r.FeatureBranch.RelatedCommits()
+	if err != nil {
+		return "", fmt.Errorf("failed to read commits for %s. %v", r.FeatureBranch, err)
+	}
+
+	// only the commits made on the feature branch since it forked from the default branch are considered, so earlier releases of the same or a similar ticket cannot change the bump
+	if len(commits) == 0 {
+		return "", fmt.Errorf("no commits for %s were made on %s since it forked from %s to infer a release from. specify -major, -minor or -patch instead", feature.Ticket, r.FeatureBranch, r.DefaultBranch)
+	}
+
+	level, reasons := conventional.InferBump(conventional.ParseAll(commits, feature.Ticket))
+
+	logging.Instance().Infof("inferred a %s release from %d commit(s) made on %s since it forked from %s:", level, len(commits), r.FeatureBranch, r.DefaultBranch)
+	for _, reason := range reasons {
+		logging.Instance().Infof("  - %s", reason)
+	}
+
+	next, err := nextReleaseVersion(r.LastTag, FinishAction(level), fc.preRelease)
+	if err != nil {
+		return "", err
+	}
+
+	proceed, err := prompt.Confirm(fmt.Sprintf("continue with %s release %s -> %s", level, r.LastTag, next), prompt.DefaultYes)
+	if err != nil {
+		return "", err
+	}
+	if !proceed {
+		logging.Instance().Info("re-run with -major, -minor or -patch to choose the release type explicitly")
+		return "", nil
+	}
+
+	return FinishAction(level), nil
+}
+
+func releaseTags(version semver.Semver) []string {
+	if version.IsPreRelease() {
+		return []string{version.String()}
+	}
+	return []string{version.String(), version.Major()}
+}
+
+func releaseCommitMessage(feature *models.Feature) string {
+	return strings.Join([]string{feature.Ticket, feature.Comment}, " ")
+}
+
+type releasePlan struct {
+	recorder *git.RecordingBackend
+
+	changelog string
+	release string
+	versionFiles string
+	files []string
+}
+
+func (fc *FinishCommand) printPlan(r *git.Repository, state *finishState) (*finishResult, error) {
+	feature, updatedVersion := state.Feature, state.Version
+
+	for _, step := range fc.releaseSteps(r, state) {
+		logging.Instance().Debugf("planning finish step: %s", step.name)
 
-		if err := changelog.WriteChangelogToFile(changelogLines); err != nil {
-			return fmt.Errorf("failed to write changelog entry. %v", err)
+		if err := step.run(); err != nil {
+			return nil, fmt.Errorf("failed to plan finish step '%s'. %v", step.name, err)
 		}
 	}
 
-	if err := os.RemoveAll(GOGDir); err != nil {
-		return fmt.Errorf("failed to remove GOG directory. %v", err)
+	w := output.Stdout()
+
+	fmt.Fprintf(w, "\n-------====== Release Plan for %s ======-------\n\n", feature.Ticket)
+
+	if feature.Component != "" {
+		fmt.Fprintf(w, "Component:      %s (%s)\n", feature.Component, changelog.File())
+	}
+
+	if fc.pullRequest {
+		fmt.Fprintf(w, "Version:        %s -> %s (%s, assigned by 'gog release' once merged)\n", r.LastTag, updatedVersion, fc.action)
+		fmt.Fprintf(w, "Pull request:   %s (%s -> %s)\n", releaseCommitMessage(feature), r.FeatureBranch, r.DefaultBranch)
+	} else {
+		fmt.Fprintf(w, "Version:        %s -> %s (%s)\n", r.LastTag, updatedVersion, fc.action)
+		fmt.Fprintf(w, "Squash commit:  %s\n", releaseCommitMessage(feature))
+
+		if fc.noTag {
+			fmt.Fprintln(w, "Tags:           (none, -no-tag specified)")
+		} else {
+			fmt.Fprintf(w, "Tags:           %s\n", strings.Join(releaseTags(updatedVersion), ", "))
+		}
+
+		if fc.plan.release != "" {
+			fmt.Fprintf(w, "Forge release:  %s\n", fc.plan.release)
+		}
+
+		fmt.Fprintf(w, "Deleted:        %s (local), origin/%s (remote)\n", r.FeatureBranch, r.FeatureBranch)
 	}
 
-	if err := r.StageChanges(); err != nil {
-		return fmt.Errorf("failed to stage removal of GOG metadata folder on %s. %v", r.CurrentBranch, err)
+	if fc.plan.changelog != "" {
+		if fc.pullRequest {
+			fmt.Fprintf(w, "\n-------====== Pull Request Body ======-------\n\n")
+		} else {
+			fmt.Fprintf(w, "\n-------====== %s Entry ======-------\n\n", changelog.File())
+		}
+		fmt.Fprintln(w, strings.TrimRight(fc.plan.changelog, "\n"))
 	}
 
-	if err := r.CommitChanges("remove GOG metadata folder"); err != nil {
-		return err
+	if fc.plan.versionFiles != "" {
+		fmt.Fprintf(w, "\n-------====== Version Files ======-------\n\n")
+		fmt.Fprint(w, fc.plan.versionFiles)
 	}
 
-	if err := r.Rebase(); err != nil {
-		return fmt.Errorf("failed to rebase commits into new release. %v", err)
+	fmt.Fprintf(w, "\n-------====== File Changes ======-------\n\n")
+	for _, f := range fc.plan.files {
+		fmt.Fprintf(w, "  %s\n", f)
 	}
 
-	if err := r.CheckoutBranch(r.DefaultBranch, false, false); err != nil {
-		return fmt.Errorf("failed to checkout branch (%s). %v", r.DefaultBranch, err)
+	fmt.Fprintf(w, "\n-------====== Git Operations ======-------\n\n")
+	for _, op := range fc.plan.recorder.Operations() {
+		fmt.Fprintf(w, "  %s\n", op)
 	}
 
-	if err := r.PullChanges(); err != nil {
-		return fmt.Errorf("failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
+	fmt.Fprintln(w, "\n-------================================-------")
+
+	logging.Instance().Info("dry-run complete ... no changes were made to the repository or remote")
+
+	result := &finishResult{
+		Ticket: feature.Ticket,
+		Component: feature.Component,
+		Action: fc.action,
+		Version: updatedVersion.String(),
+		PreviousVersion: r.LastTag.String(),
+		Tags: []string{},
+		Branch: r.DefaultBranch.Name,
+		FeatureBranch: r.FeatureBranch.Name,
+		DryRun: true,
+		Changelog: fc.plan.changelog,
+		VersionFiles: fc.plan.versionFiles,
+		Files: fc.plan.files,
+		Operations: fc.plan.recorder.Operations(),
+	}
+	if !fc.noTag && !fc.pullRequest {
+		result.Tags = releaseTags(updatedVersion)
 	}
 
-	if err := r.SquashMerge(); err != nil {
-		return fmt.Errorf("failed to perform squash-merge for new release. %v", err)
+	return result, nil
+}
+
+type finishStep struct {
+	name string
+	remote string
+	run func() error
+
+	// retry steps are safe to rerun, so a failure keeps the finish in progress instead of rolling back
+	retry bool
+}
+
+func stackedStep(r *git.Repository, feature *models.Feature) finishStep {
+	return finishStep{name: "rebase-stacked", run: func() error {
+		logging.Instance().Infof("parent feature %s has been merged ... rebasing %s onto %s", feature.ParentFeature, feature.Ticket, r.DefaultBranch)
+
+		if err := r.RebaseOnto(feature.ParentCommit); err != nil {
+			return fmt.Errorf("failed to rebase %s onto %s after parent feature was merged. %v", feature.Ticket, r.DefaultBranch, err)
+		}
+
+		feature.ParentFeature, feature.ParentCommit = "", ""
+		return nil
+	}}
+}
+
+func (fc *FinishCommand) releaseSteps(r *git.Repository, state *finishState) []finishStep {
+	feature, updatedVersion, j := state.Feature, state.Version, state.Journal
+
+	if fc.pullRequest {
+		return fc.pullRequestSteps(r, feature, updatedVersion)
 	}
 
-	if err := r.StageChanges(); err != nil {
-		return fmt.Errorf("failed to stage final changes to %s. %v", r.CurrentBranch, err)
+	var steps []finishStep
+
+	if feature.IsStacked() {
+		steps = append(steps, stackedStep(r, feature))
 	}
 
-	if err := r.CommitChanges(strings.Join([]string{feature.Jira, feature.Comment}, " ")); err != nil {
-		return fmt.Errorf("failed to commit final changes to %s. %v", r.CurrentBranch, err)
+	if !fc.noTag && (!fc.noChangelog || fc.publish) {
+		steps = append(steps, finishStep{name: "write-changelog", run: func() error {
+			// adding the entry first moves anything from the Unreleased block into it, so the release notes include it too
+			changelogEntry, changelogLines, err := releaseEntry(r, feature, fc.action, updatedVersion, fc.noChangelog)
+			if err != nil {
+				return err
+			}
+
+			// kept in the finish state since the feature branch is gone by the time the release is published
+			state.ReleaseNotes = changelogEntry.String()
+
+			if fc.plan != nil {
+				fc.plan.changelog = state.ReleaseNotes
+				if !fc.noChangelog {
+					fc.plan.files = append(fc.plan.files, "update " + changelog.File())
+				}
+				return nil
+			}
+
+			if fc.noChangelog {
+				return nil
+			}
+
+			if err := changelog.WriteChangelogToFile(changelogLines); err != nil {
+				return fmt.Errorf("failed to write changelog entry. %v", err)
+			}
+			return nil
+		}})
 	}
 
-	if err := r.Push(); err != nil {
-		return fmt.Errorf("failed to push final changes to %s. %v", r.CurrentBranch, err)
+	if !fc.noTag {
+		steps = append(steps, finishStep{name: "bump-version-files", run: func() error {
+			return bumpVersionFiles(updatedVersion, fc.plan)
+		}})
 	}
 
+	steps = append(steps,
+		finishStep{name: "remove-metadata", run: func() error {
+			if fc.plan != nil {
+				fc.plan.files = append(fc.plan.files, "remove .gog")
+				return nil
+			}
+
+			if err := os.RemoveAll(common.GOGPath()); err != nil {
+				return fmt.Errorf("failed to remove GOG directory. %v", err)
+			}
+			return nil
+		}},
+		finishStep{name: "commit-metadata", run: func() error {
+			if err := r.StageChanges(); err != nil {
+				return fmt.Errorf("failed to stage removal of GOG metadata folder on %s. %v", r.CurrentBranch, err)
+			}
+			return r.CommitChanges("remove GOG metadata folder")
+		}},
+		finishStep{name: "rebase", run: func() error {
+			if err := r.Rebase(); err != nil {
+				return fmt.Errorf("failed to rebase commits into new release. %v", err)
+			}
+			return nil
+		}},
+		finishStep{name: "checkout-default", run: func() error {
+			if err := r.CheckoutBranch(r.DefaultBranch, false, false); err != nil {
+				return fmt.Errorf("failed to checkout branch (%s). %v", r.DefaultBranch, err)
+			}
+			return nil
+		}},
+		finishStep{name: "pull-default", run: func() error {
+			if err := r.PullChanges(); err != nil {
+				return fmt.Errorf("failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
+			}
+			return nil
+		}},
+		finishStep{name: "squash-merge", run: func() error {
+			if err := r.SquashMerge(); err != nil {
+				return fmt.Errorf("failed to perform squash-merge for new release. %v", err)
+			}
+			return nil
+		}},
+		finishStep{name: "commit-release", run: func() error {
+			if err := r.StageChanges(); err != nil {
+				return fmt.Errorf("failed to stage final changes to %s. %v", r.CurrentBranch, err)
+			}
+
+			if err := r.CommitChanges(releaseCommitMessage(feature)); err != nil {
+				return fmt.Errorf("failed to commit final changes to %s. %v", r.CurrentBranch, err)
+			}
+			return nil
+		}},
+	)
+
+	// the tags are created before anything is pushed, so that a release which fails to sign is rolled back entirely
 	if !fc.noTag {
-		if err := feature.CreateReleaseTags(r, updatedVersion); err != nil {
-			return fmt.Errorf("failed to create release tags. %v", err)
-		}
+		steps = append(steps, finishStep{name: "create-tags", run: func() error {
+			for _, tag := range releaseTags(updatedVersion) {
+				j.RecordTag(r, tag)
+			}
+
+			if err := feature.CreateReleaseTags(r, updatedVersion, state.ReleaseNotes, tagSigning(fc.sign)); err != nil {
+				return fmt.Errorf("failed to create release tags. %v", err)
+			}
+			return nil
+		}})
+	}
 
-		if err := r.PushTags(); err != nil {
-			return fmt.Errorf("failed to publish release tags to remote. %v", err)
+	steps = append(steps, finishStep{name: "push-release", remote: fmt.Sprintf("pushed release commit for %s to origin/%s", feature.Ticket, r.DefaultBranch), run: func() error {
+		if err := r.Push(); err != nil {
+			return fmt.Errorf("failed to push final changes to %s. %v", r.CurrentBranch, err)
 		}
+		return nil
+	}})
+
+	if !fc.noTag {
+		steps = append(steps,
+			finishStep{name: "push-tags", remote: fmt.Sprintf("pushed release tags %s to origin", strings.Join(releaseTags(updatedVersion), ", ")), run: func() error {
+				if err := r.PushTags(); err != nil {
+					return fmt.Errorf("failed to publish release tags to remote. %v", err)
+				}
+				return nil
+			}},
+		)
+	}
+
+	steps = append(steps,
+		finishStep{name: "delete-local-branch", run: func() error {
+			if err := r.DeleteLocalBranch(r.FeatureBranch); err != nil {
+				return fmt.Errorf("failed to delete local feature branch for %s. %v", feature.Ticket, err)
+			}
+			return nil
+		}},
+		finishStep{name: "delete-remote-branch", remote: fmt.Sprintf("deleted remote feature branch origin/%s", r.FeatureBranch), run: func() error {
+			if err := r.DeleteRemoteBranch(r.FeatureBranch); err != nil {
+				return fmt.Errorf("failed to delete remote feature branch for %s. %v", feature.Ticket, err)
+			}
+			return nil
+		}},
+	)
+
+	if fc.publish {
+		steps = append(steps, finishStep{name: "publish-release", remote: fmt.Sprintf("published forge release %s", updatedVersion), retry: true, run: func() error {
+			if fc.plan != nil {
+				assets, err := releaseAssets()
+				if err != nil {
+					return err
+				}
+
+				fc.plan.release = describeRelease(updatedVersion, assets)
+				return nil
+			}
+
+			release, err := publishRelease(fc.forge, updatedVersion, state.ReleaseNotes)
+			if err != nil {
+				return err
+			}
+
+			fc.releasePublished = release
+			return nil
+		}})
+	}
+
+	return steps
+}
+
+func openForge(r *git.Repository) (forge.Forge, error) {
+	remoteURL, err := r.RemoteURL()
+	if err != nil {
+		return nil, output.Errorf(output.CodeForge, "failed to read the origin url for pull requests. %v", err)
+	}
+
+	f, err := forge.New(remoteURL)
+	if err != nil {
+		return nil, output.Errorf(output.CodeForge, "failed to set up forge for pull requests. %v", err)
+	}
+
+	return f, nil
+}
+
+// pullRequestBody is the changelog entry for the feature, as it will be released once merged
+func pullRequestBody(r *git.Repository, feature *models.Feature, action FinishAction, updatedVersion semver.Semver, changes []models.Change) (string, error) {
+	entry := changelog.NewChangelogEntry(feature, r, updatedVersion, action == "MAJOR" || action == "MINOR")
+	entry.Changes = changes
+
+	if _, err := entry.Build(); err != nil {
+		return "", output.Errorf(output.Code(err), "failed to build the changelog entry. %v", err)
+	}
+
+	return strings.TrimRight(entry.String(), "\n") + "\n\n_The final version is assigned by `gog release` once this pull request is merged._\n", nil
+}
+
+// pullRequestSteps push the rebased feature with a pending release record and open a pull request for it
+func (fc *FinishCommand) pullRequestSteps(r *git.Repository, feature *models.Feature, updatedVersion semver.Semver) []finishStep {
+	var steps []finishStep
+
+	if feature.IsStacked() {
+		steps = append(steps, stackedStep(r, feature))
 	}
 
-	if err := r.DeleteBranch(r.FeatureBranch); err != nil {
-		return fmt.Errorf("failed to delete existing feature branch for %s. %v", feature.Jira, err)
+	steps = append(steps,
+		finishStep{name: "write-pending-release", run: func() error {
+			changes, err := feature.Changes(r)
+			if err != nil {
+				return fmt.Errorf("failed to read changes for %s. %v", feature.Ticket, err)
+			}
+
+			pending := models.NewPendingRelease(feature, string(fc.action), fc.preRelease, fc.noChangelog, fc.noTag, changes)
+			pending.Publish = fc.publish
+			pending.Sign = fc.sign
+
+			if fc.plan != nil {
+				if fc.plan.changelog, err = pullRequestBody(r, feature, fc.action, updatedVersion, changes); err != nil {
+					return err
+				}
+				fc.plan.files = append(fc.plan.files, "remove .gog/feature.json", fmt.Sprintf("add .gog/releases/%s.json", feature.Ticket))
+				return nil
+			}
+
+			if err := os.Remove(common.GOGPath() + "/feature.json"); err != nil {
+				return fmt.Errorf("failed to remove feature file. %v", err)
+			}
+
+			if err := pending.Save(); err != nil {
+				return fmt.Errorf("failed to record pending release for %s. %v", feature.Ticket, err)
+			}
+			return nil
+		}},
+		finishStep{name: "commit-metadata", run: func() error {
+			if err := r.StageChanges(); err != nil {
+				return fmt.Errorf("failed to stage pending release on %s. %v", r.CurrentBranch, err)
+			}
+			return r.CommitChanges("record pending GOG release")
+		}},
+		finishStep{name: "rebase", run: func() error {
+			if err := r.RebaseRemote(); err != nil {
+				return fmt.Errorf("failed to rebase %s onto %s. %v", r.FeatureBranch, r.DefaultBranch, err)
+			}
+			return nil
+		}},
+		finishStep{name: "push-branch", remote: fmt.Sprintf("pushed rebased feature branch origin/%s", r.FeatureBranch), run: func() error {
+			if err := r.ForcePush(); err != nil {
+				return fmt.Errorf("failed to push %s. %v", r.FeatureBranch, err)
+			}
+			return nil
+		}},
+		finishStep{name: "open-pull-request", remote: fmt.Sprintf("opened pull request for %s", feature.Ticket), run: func() error {
+			if fc.plan != nil {
+				return nil
+			}
+
+			pending, err := models.LoadPendingRelease(feature.Ticket)
+			if err != nil {
+				return fmt.Errorf("failed to read pending release for %s. %v", feature.Ticket, err)
+			}
+
+			body, err := pullRequestBody(r, feature, fc.action, updatedVersion, pending.Changes)
+			if err != nil {
+				return err
+			}
+
+			pr, err := fc.forge.CreatePullRequest(forge.PullRequest{
+				Title: releaseCommitMessage(feature),
+				Body: body,
+				Head: r.FeatureBranch.Name,
+				Base: r.DefaultBranch.Name,
+			})
+			if err != nil {
+				return output.WithCode(output.CodeForge, err)
+			}
+
+			logging.Instance().Infof("pull request #%d for %s: %s", pr.Number, feature.Ticket, pr.URL)
+
+			fc.pullRequestOpened = pr
+			return nil
+		}},
+	)
+
+	return steps
+}
+
+func rollback(r *git.Repository, j *journal.Journal, cause error) error {
+	logging.Instance().Warnf("feature release failed after completing steps %v ... rolling back local changes", j.Completed)
+
+	if err := j.Rollback(r); err != nil {
+		logging.Instance().Errorf("failed to roll back local changes, manual cleanup may be required. %v", err)
+	} else {
+		logging.Instance().Infof("restored %s, GOG metadata and %s to their state before finish", j.FeatureBranch, changelog.File())
 	}
 
-	logging.Instance().Infof("Successfully created new feature release for %s!", feature.Jira)
+	reportRemote(j)
+
+	return output.WithCode(output.CodeGit, cause)
+}
 
-	return nil
+func reportRemote(j *journal.Journal) {
+	if len(j.Remote) == 0 {
+		logging.Instance().Info("no changes reached the remote")
+	} else {
+		logging.Instance().Warn("the following changes already reached the remote and were not rolled back:")
+		for _, change := range j.Remote {
+			logging.Instance().Warnf("  - %s", change)
+		}
+	}
 }
 
 func (fc *FinishCommand) Name() string {
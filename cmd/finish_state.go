@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/journal"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/semver"
+)
+
+type finishState struct {
+	Action FinishAction `json:"action"`
+	Version semver.Semver `json:"version"`
+	NoChangelog bool `json:"no_changelog"`
+	NoTag bool `json:"no_tag"`
+	PreRelease string `json:"pre_release,omitempty"`
+	PullRequest bool `json:"pull_request,omitempty"`
+	Publish bool `json:"publish,omitempty"`
+	Sign bool `json:"sign,omitempty"`
+	ReleaseNotes string `json:"release_notes,omitempty"`
+
+	Feature *models.Feature `json:"feature"`
+	Journal *journal.Journal `json:"journal"`
+}
+
+func finishStatePath(b git.Backend) (string, error) {
+	gitDir, err := b.GitDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(gitDir, "gog", "finish.json"), nil
+}
+
+func finishInProgress(b git.Backend) bool {
+	path, err := finishStatePath(b)
+	if err != nil {
+		return false
+	}
+
+	return common.PathExists(path)
+}
+
+func loadFinishState(b git.Backend) (*finishState, error) {
+	path, err := finishStatePath(b)
+	if err != nil {
+		return nil, err
+	}
+
+	stateBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state *finishState
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return nil, err
+	}
+
+	logging.Instance().Debugf("loaded finish state for %s from %s", state.Feature, path)
+
+	return state, nil
+}
+
+func (s *finishState) save(b git.Backend) error {
+	path, err := finishStatePath(b)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	stateBytes, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	logging.Instance().Debugf("saving finish state (%d bytes) to %s", len(stateBytes), path)
+
+	return os.WriteFile(path, stateBytes, 0600)
+}
+
+func clearFinishState(b git.Backend) error {
+	path, err := finishStatePath(b)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"testing"
+
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/journal"
+	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/semver"
+)
+
+func TestFinishStateSaveLoad(t *testing.T) {
+	b := git.NewMemoryBackend(t.TempDir(), "main")
+
+	if finishInProgress(b) {
+		t.Fatal("no finish should be in progress before the state is saved")
+	}
+
+	state := &finishState{
+		Action: "MINOR",
+		Version: semver.MustParse("1.3.0-rc.1"),
+		PreRelease: "rc",
+		Publish: true,
+		ReleaseNotes: "## [v1.3.0-rc.1]\n",
+		Feature: &models.Feature{Ticket: "ABC-1", Comment: "login"},
+		Journal: &journal.Journal{FeatureBranch: "ABC-1", Completed: []string{"squash-merge"}, Paused: "rebase"},
+	}
+	if err := state.save(b); err != nil {
+		t.Fatalf("save failed. %v", err)
+	}
+	if !finishInProgress(b) {
+		t.Error("a finish should be in progress once the state is saved")
+	}
+
+	loaded, err := loadFinishState(b)
+	if err != nil {
+		t.Fatalf("loadFinishState failed. %v", err)
+	}
+	if loaded.Action != state.Action || !loaded.Version.Equal(state.Version) || loaded.PreRelease != "rc" || !loaded.Publish || loaded.ReleaseNotes != state.ReleaseNotes {
+		t.Errorf("loaded %+v, want %+v", loaded, state)
+	}
+	if loaded.Feature.Ticket != "ABC-1" || !loaded.Journal.Done("squash-merge") || loaded.Journal.Paused != "rebase" {
+		t.Errorf("loaded feature %+v and journal %+v", loaded.Feature, loaded.Journal)
+	}
+
+	if err := clearFinishState(b); err != nil {
+		t.Fatal(err)
+	}
+	if finishInProgress(b) {
+		t.Error("no finish should be in progress once the state is cleared")
+	}
+	if err := clearFinishState(b); err != nil {
+		t.Errorf("clearing a cleared state failed. %v", err)
+	}
+}
+
+func TestReleaseStateSaveLoad(t *testing.T) {
+	b := git.NewMemoryBackend(t.TempDir(), "main")
+
+	state := &releaseState{
+		Pending: []*models.PendingRelease{{Feature: &models.Feature{Ticket: "ABC-1"}, Action: "PATCH", Publish: true}},
+		Previous: map[string]semver.Semver{"ABC-1": semver.MustParse("1.2.0")},
+		Versions: map[string]semver.Semver{"ABC-1": semver.MustParse("1.2.1")},
+		Notes: map[string]string{"ABC-1": "## [v1.2.1]\n"},
+		Sign: true,
+		Journal: &journal.Journal{Completed: []string{"push-release"}, Remote: []string{"pushed release commits to origin/main"}},
+	}
+	if err := state.save(b); err != nil {
+		t.Fatalf("save failed. %v", err)
+	}
+	if !releaseInProgress(b) || finishInProgress(b) {
+		t.Error("only a release should be in progress once its state is saved")
+	}
+
+	loaded, err := loadReleaseState(b)
+	if err != nil {
+		t.Fatalf("loadReleaseState failed. %v", err)
+	}
+	if len(loaded.Pending) != 1 || loaded.Pending[0].Feature.Ticket != "ABC-1" || !loaded.Pending[0].Publish || !loaded.Sign {
+		t.Errorf("loaded %+v, want %+v", loaded, state)
+	}
+	if !loaded.Versions["ABC-1"].Equal(semver.MustParse("1.2.1")) || !loaded.Previous["ABC-1"].Equal(semver.MustParse("1.2.0")) || loaded.Notes["ABC-1"] != state.Notes["ABC-1"] {
+		t.Errorf("loaded versions %v, previous %v and notes %v", loaded.Versions, loaded.Previous, loaded.Notes)
+	}
+	if !loaded.Journal.Done("push-release") || len(loaded.Journal.Remote) != 1 {
+		t.Errorf("loaded journal %+v", loaded.Journal)
+	}
+
+	if err := clearReleaseState(b); err != nil {
+		t.Fatal(err)
+	}
+	if releaseInProgress(b) {
+		t.Error("no release should be in progress once the state is cleared")
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"sykesdev.ca/gog/internal/semver"
+)
+
+// TestMain keeps the tests away from the user's GOG config and any git project around the working directory
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gog-cmd-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("GIT_CEILING_DIRECTORIES", dir)
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestNextReleaseVersion(t *testing.T) {
+	tests := []struct {
+		current string
+		action FinishAction
+		pre string
+		want string
+		valid bool
+	}{
+		{"1.2.0", "MINOR", "", "1.3.0", true},
+		{"1.2.0", "MINOR", "rc", "1.3.0-rc.1", true},
+		{"1.3.0-rc.1", "MINOR", "rc", "1.3.0-rc.2", true},
+		{"1.3.0-rc.1", "MINOR", "", "1.3.0", true},
+		{"1.3.0-beta.2", "MINOR", "rc", "1.3.0-rc.1", true},
+		{"1.3.0-rc.1", "PATCH", "beta", "", false},
+		{"1.3.0-rc.1", "MINOR", "alpha", "", false},
+		{"1.3.0-rc.1", "MAJOR", "beta", "2.0.0-beta.1", true},
+	}
+
+	for _, tt := range tests {
+		got, err := nextReleaseVersion(semver.MustParse(tt.current), tt.action, tt.pre)
+		if (err == nil) != tt.valid {
+			t.Errorf("nextReleaseVersion(%s, %s, %q) error = %v, want valid: %t", tt.current, tt.action, tt.pre, err, tt.valid)
+			continue
+		}
+		if tt.valid && got.NoPrefix() != tt.want {
+			t.Errorf("nextReleaseVersion(%s, %s, %q) = %s, want %s", tt.current, tt.action, tt.pre, got.NoPrefix(), tt.want)
+		}
+	}
+}
@@ -2,8 +2,9 @@ package cmd
 
 type Runnable interface {
 	Init([]string) error
-	Run() error
+	// Run returns a structured result describing what the command did, printed with '--output json'
+	Run() (interface{}, error)
 	Name() string
 	Alias() string
 	Help()
-}
\ No newline at end of file
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/forge"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
+	"sykesdev.ca/gog/internal/semver"
+)
+
+// releaseAssets expands the release.assets glob patterns from the root of the project
+func releaseAssets() ([]string, error) {
+	projectRoot, err := common.GitProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	var assets []string
+	for _, pattern := range config.AppConfig().ReleaseAssets() {
+		matches, err := filepath.Glob(filepath.Join(projectRoot, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid release asset pattern '%s'. %v", pattern, err)
+		}
+
+		if len(matches) == 0 {
+			logging.Instance().Warnf("release asset pattern '%s' did not match any files", pattern)
+		}
+		assets = append(assets, matches...)
+	}
+
+	return assets, nil
+}
+
+// describeRelease summarizes the forge release which would be published for version
+func describeRelease(version semver.Semver, assets []string) string {
+	if len(assets) == 0 {
+		return fmt.Sprintf("%s (changelog entry as notes, no assets)", version)
+	}
+
+	names := make([]string, len(assets))
+	for i, a := range assets {
+		names[i] = filepath.Base(a)
+	}
+	return fmt.Sprintf("%s (changelog entry as notes, assets: %s)", version, strings.Join(names, ", "))
+}
+
+// publishRelease creates or updates the forge release for version with the changelog entry as its notes
+func publishRelease(f forge.Forge, version semver.Semver, notes string) (*forge.Release, error) {
+	assets, err := releaseAssets()
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := forge.PublishRelease(f, forge.Release{
+		Tag: version.String(),
+		Name: version.String(),
+		Body: notes,
+		PreRelease: version.IsPreRelease(),
+	}, assets)
+	if err != nil {
+		return nil, output.Errorf(output.CodeForge, "failed to publish release %s. %v", version, err)
+	}
+
+	logging.Instance().Infof("published release %s with %d asset(s): %s", version, len(release.Assets), release.URL)
+	return release, nil
+}
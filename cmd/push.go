@@ -1,16 +1,17 @@
 package cmd
 
 import (
-	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"sykesdev.ca/gog/config"
 	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/git"
 	"sykesdev.ca/gog/internal/logging"
 	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/output"
 )
 
 type PushCommand struct {
@@ -19,6 +20,16 @@ type PushCommand struct {
 	name string
 	alias string
 	message string
+	changeType string
+}
+
+type pushResult struct {
+	Ticket string `json:"ticket"`
+	Branch string `json:"branch"`
+	Message string `json:"message"`
+	Commit string `json:"commit"`
+	Category string `json:"category,omitempty"`
+	TestCount int `json:"test_count"`
 }
 
 func NewPushCommand() *PushCommand {
@@ -28,6 +39,8 @@ func NewPushCommand() *PushCommand {
 		fs: flag.NewFlagSet("push", flag.ContinueOnError),
 	}
 
+	pc.fs.StringVar(&pc.changeType, "type", "", "the kind of change being pushed (eg. fix or security). adds a 'Changelog:' trailer to the commit which places it in that section of the changelog")
+
 	pc.fs.Usage = pc.Help
 
 	return pc
@@ -35,7 +48,7 @@ func NewPushCommand() *PushCommand {
 
 func (fc *PushCommand) Help() {
 	fmt.Printf(
-`Usage: %s (%s | %s) [message] [-h] [-help]
+`Usage: %s (%s | %s) [-type <type>] [message] [-h] [-help]
 
 -------====== Push Arguments ======-------
 
@@ -58,54 +71,71 @@ func (pc *PushCommand) Init(args []string) error {
 	return err
 }
 
-func (pc *PushCommand) Run() error {
+func (pc *PushCommand) Run() (interface{}, error) {
 	r, err := git.NewRepository()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	GOGDir := common.GOGPath()
 
 	if !common.PathExists(GOGDir + "/feature.json") {
-		return errors.New("feature file not found ... there may not be a GOG feature on this branch")
+		return nil, output.Errorf(output.CodeNoFeature, "feature file not found ... there may not be a GOG feature on this branch")
 	}
 
 	feature, err := models.NewFeatureFromFile()
 	if err != nil {
-		return fmt.Errorf("failed to read feature from features file (%s). %v", GOGDir + "/feature.json", err)
+		return nil, output.Errorf(output.CodeNoFeature, "failed to read feature from features file (%s). %v", GOGDir + "/feature.json", err)
 	}
 	defer feature.Save()
 	
 	r.FeatureBranch = r.CurrentBranch
 
+	var category string
+	if pc.changeType != "" {
+		var ok bool
+		if category, ok = config.AppConfig().ChangelogCategory(pc.changeType); !ok {
+			return nil, output.Errorf(output.CodeUsage, "unknown change type '%s'. must be one of %s", pc.changeType, strings.Join(config.AppConfig().ChangelogTypes(), ", "))
+		}
+	}
+
 	if pc.message == "" {
-		pc.message = fmt.Sprintf("%s Test Build (%d)", feature.Jira, feature.TestCount)
+		pc.message = fmt.Sprintf("%s Test Build (%d)", feature.Ticket, feature.TestCount)
 		feature.UpdateTestCount()
 	} else {
-		pc.message = strings.Join([]string{feature.Jira, pc.message}, " ")
+		pc.message = strings.Join([]string{feature.Ticket, pc.message}, " ")
+	}
+
+	if category != "" {
+		pc.message += "\n\nChangelog: " + strings.ToLower(category)
 	}
 
 	if err := r.StageChanges(); err != nil {
-		return fmt.Errorf("failed to stage current changes for %s. %v", r.CurrentBranch, err)
+		return nil, output.Errorf(output.CodeGit, "failed to stage current changes for %s. %v", r.CurrentBranch, err)
 	}
 
 	if err := r.CommitChanges(pc.message); err != nil {
-		return fmt.Errorf("failed to commit current changes for %s. %v", r.CurrentBranch, err)
+		return nil, output.Errorf(output.CodeGit, "failed to commit current changes for %s. %v", r.CurrentBranch, err)
 	}
 
 	if r.CurrentBranch.RemoteExists {
 		if err := r.PullChanges(); err != nil {
-			return fmt.Errorf("failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
+			return nil, output.Errorf(output.CodeGit, "failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
 		}
 	}
 
 	if err := r.Push(); err != nil {
-		return fmt.Errorf("failed to push local commits to remote. %v", err)
+		return nil, output.Errorf(output.CodeGit, "failed to push local commits to remote. %v", err)
+	}
+
+	commit, err := r.HeadCommit()
+	if err != nil {
+		logging.Instance().Debugf("failed to read the pushed commit. %v", err)
 	}
 
 	logging.Instance().Info("Successfully pushed changes to remote feature!")
 
-	return nil
+	return &pushResult{Ticket: feature.Ticket, Branch: r.CurrentBranch.Name, Message: pc.message, Commit: commit, Category: category, TestCount: feature.TestCount}, nil
 }
 
 func (pc *PushCommand) Name() string {
@@ -0,0 +1,519 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/changelog"
+	"sykesdev.ca/gog/internal/forge"
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/journal"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/output"
+	"sykesdev.ca/gog/internal/semver"
+)
+
+type ReleaseCommand struct {
+	fs *flag.FlagSet
+
+	name string
+	publish bool
+	sign bool
+
+	resume bool
+	abort bool
+
+	forge forge.Forge
+	published map[string]*forge.Release
+}
+
+type releasedFeature struct {
+	Ticket string `json:"ticket"`
+	Component string `json:"component,omitempty"`
+	Version string `json:"version"`
+	Tags []string `json:"tags"`
+	Release *forge.Release `json:"release,omitempty"`
+}
+
+type releaseResult struct {
+	Releases []releasedFeature `json:"releases"`
+	Commit string `json:"commit,omitempty"`
+}
+
+func NewReleaseCommand() *ReleaseCommand {
+	rc := &ReleaseCommand{
+		name: "release",
+		published: map[string]*forge.Release{},
+		fs: flag.NewFlagSet("release", flag.ContinueOnError),
+	}
+
+	rc.fs.BoolVar(&rc.sign, "sign", false, "signs the release tags with GPG or SSH (see release.signing_format and release.signing_key in config). enabled by default with release.sign in config, or for features finished with -sign")
+	rc.fs.BoolVar(&rc.publish, "publish", false, "publishes a release on the forge for each new tag with its changelog entry as the notes and any release.assets attached. enabled by default with release.publish in config")
+
+	rc.fs.BoolVar(&rc.resume, "continue", false, "retries the remaining steps of a release which stopped after its release commits were pushed, eg. publishing it on the forge")
+	rc.fs.BoolVar(&rc.abort, "abort", false, "stops retrying the remaining steps of a release which stopped after its release commits were pushed. what reached the remote is left as it is")
+
+	rc.fs.Usage = rc.Help
+
+	return rc
+}
+
+func (rc *ReleaseCommand) Help() {
+	fmt.Printf(
+`Usage: %s %s [-publish] [-sign] [-continue | -abort] [-h] [-help]
+
+-------====== Release Arguments ======-------
+
+Tags and records in CHANGELOG.md every feature which was finished with 'gog finish -pr' and
+has since been merged into the default branch. Run from the default branch once the pull request is merged.
+
+`, os.Args[0], rc.name)
+
+	rc.fs.PrintDefaults()
+
+	fmt.Println("\n-------================================-------")
+}
+
+func (rc *ReleaseCommand) Init(args []string) error {
+	err := rc.fs.Parse(args)
+
+	if rc.resume && rc.abort {
+		return errors.New("cannot specify both -continue and -abort for the same release")
+	}
+
+	return err
+}
+
+func (rc *ReleaseCommand) Run() (interface{}, error) {
+	if rc.resume {
+		return rc.continueRelease()
+	}
+
+	if rc.abort {
+		return rc.abortRelease()
+	}
+
+	if finishInProgress(git.DefaultBackend()) {
+		return nil, output.Errorf(output.CodeInProgress, "a feature release is already in progress for this repository. run 'gog finish -continue' or 'gog finish -abort' first")
+	}
+
+	if releaseInProgress(git.DefaultBackend()) {
+		return nil, output.Errorf(output.CodeInProgress, "a release is already in progress for this repository. run 'gog release -continue' to finish its remaining steps, or 'gog release -abort' to stop retrying them")
+	}
+
+	r, err := git.NewRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.CurrentBranch.Name != r.DefaultBranch.Name {
+		return nil, output.Errorf(output.CodeUsage, "releases must be created from %s (currently on %s)", r.DefaultBranch, r.CurrentBranch)
+	}
+
+	if err := r.PullChanges(); err != nil {
+		return nil, output.Errorf(output.CodeGit, "failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
+	}
+
+	pending, err := models.PendingReleases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending releases. %v", err)
+	}
+
+	if len(pending) == 0 {
+		logging.Instance().Info("there are no merged features waiting to be released")
+		return &releaseResult{Releases: []releasedFeature{}}, nil
+	}
+
+	*r.FeatureBranch = *r.CurrentBranch
+
+	j, err := journal.New(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start release journal. %v", err)
+	}
+
+	if err := j.Backup(".gog"); err != nil {
+		return nil, err
+	}
+
+	state := &releaseState{
+		Pending: pending,
+		Previous: map[string]semver.Semver{},
+		Versions: map[string]semver.Semver{},
+		Notes: map[string]string{},
+		Publish: rc.publish,
+		Sign: rc.sign,
+		Journal: j,
+	}
+
+	defaultPrefix := config.AppConfig().TagPrefix()
+	latest := map[string]semver.Semver{}
+
+	for _, p := range pending {
+		if err := usePendingRelease(p, defaultPrefix); err != nil {
+			return nil, err
+		}
+
+		if err := j.Backup(changelog.File()); err != nil {
+			return nil, err
+		}
+
+		if err := backupVersionFiles(j); err != nil {
+			return nil, err
+		}
+
+		// components are versioned independently, so each continues from its own latest release
+		currentVersion, ok := latest[p.Feature.Component]
+		if !ok {
+			if currentVersion, err = r.LatestVersion(); err != nil {
+				return nil, output.Errorf(output.CodeGit, "failed to read the latest release of %s. %v", releaseTarget(p), err)
+			}
+		}
+
+		version, err := nextReleaseVersion(currentVersion, FinishAction(p.Action), p.PreRelease)
+		if err != nil {
+			return nil, output.Errorf(output.CodeUsage, "failed to version the pending release of %s. %v", p.Feature.Ticket, err)
+		}
+
+		state.Previous[p.Feature.Ticket] = currentVersion
+		state.Versions[p.Feature.Ticket] = version
+		latest[p.Feature.Component] = version
+	}
+
+	if err := rc.openForge(r, state); err != nil {
+		return nil, err
+	}
+
+	return rc.runSteps(r, state, defaultPrefix)
+}
+
+// openForge opens the forge once any of the pending releases is to be published
+func (rc *ReleaseCommand) openForge(r *git.Repository, state *releaseState) error {
+	for _, p := range state.Pending {
+		if rc.publishes(p) && rc.forge == nil {
+			f, err := openForge(r)
+			if err != nil {
+				return err
+			}
+			rc.forge = f
+		}
+	}
+	return nil
+}
+
+func (rc *ReleaseCommand) publishes(p *models.PendingRelease) bool {
+	return !p.NoTag && (p.Publish || rc.publish || config.AppConfig().PublishRelease())
+}
+
+// steps are every step of the release, for all pending releases of state
+func (rc *ReleaseCommand) steps(r *git.Repository, state *releaseState, defaultPrefix string) []finishStep {
+	var steps, publishSteps []finishStep
+
+	for _, p := range state.Pending {
+		p := p
+		releaseSteps, published := rc.releaseSteps(r, state, p)
+
+		// the tag prefix and changelog are global, so select those of the pending release again before each of its steps
+		for _, list := range [][]finishStep{releaseSteps, published} {
+			for i := range list {
+				run := list[i].run
+				list[i].run = func() error {
+					if err := usePendingRelease(p, defaultPrefix); err != nil {
+						return err
+					}
+					return run()
+				}
+			}
+		}
+
+		steps = append(steps, releaseSteps...)
+		publishSteps = append(publishSteps, published...)
+	}
+
+	steps = append(steps,
+		finishStep{name: "push-release", remote: fmt.Sprintf("pushed release commits to origin/%s", r.DefaultBranch), run: func() error {
+			if err := r.Push(); err != nil {
+				return fmt.Errorf("failed to push release commits to %s. %v", r.CurrentBranch, err)
+			}
+			return nil
+		}},
+		finishStep{name: "push-tags", remote: "pushed release tags to origin", run: func() error {
+			if err := r.PushTags(); err != nil {
+				return fmt.Errorf("failed to publish release tags to remote. %v", err)
+			}
+			return nil
+		}},
+	)
+
+	return append(steps, publishSteps...)
+}
+
+func (rc *ReleaseCommand) runSteps(r *git.Repository, state *releaseState, defaultPrefix string) (*releaseResult, error) {
+	j := state.Journal
+
+	for _, step := range rc.steps(r, state, defaultPrefix) {
+		if j.Done(step.name) {
+			logging.Instance().Debugf("skipping completed release step: %s", step.name)
+			continue
+		}
+
+		logging.Instance().Debugf("running release step: %s", step.name)
+
+		if err := step.run(); err != nil {
+			// once the release commits are on origin, rewinding the default branch would strand them without their tags
+			if j.Done("push-release") || step.retry {
+				return nil, rc.retryLater(r, state, step.name, err)
+			}
+			return nil, rollback(r, j, err)
+		}
+
+		if step.remote != "" {
+			j.CompleteRemote(step.name, step.remote)
+		} else {
+			j.Complete(step.name)
+		}
+
+		if j.Done("push-release") {
+			if err := state.save(r.Backend()); err != nil {
+				return nil, fmt.Errorf("failed to save release progress after %s. %v", step.name, err)
+			}
+		}
+	}
+
+	if err := clearReleaseState(r.Backend()); err != nil {
+		return nil, fmt.Errorf("failed to clear release progress. %v", err)
+	}
+
+	result := rc.result(state)
+
+	if commit, err := r.HeadCommit(); err == nil {
+		result.Commit = commit
+	}
+
+	var released []string
+	for _, feature := range result.Releases {
+		released = append(released, fmt.Sprintf("%s (%s)", feature.Ticket, feature.Version))
+	}
+
+	logging.Instance().Infof("Successfully released %s!", strings.Join(released, ", "))
+
+	return result, nil
+}
+
+// retryLater keeps a release which already reached the remote in progress, so its remaining steps can be retried with 'gog release -continue'
+func (rc *ReleaseCommand) retryLater(r *git.Repository, state *releaseState, step string, cause error) error {
+	if err := state.save(r.Backend()); err != nil {
+		logging.Instance().Errorf("failed to save release progress, the remaining steps must be completed manually. %v", err)
+	}
+
+	if strings.HasPrefix(step, "publish-release:") {
+		logging.Instance().Warnf("the release was pushed but publishing it on the forge failed during '%s'", step)
+	} else {
+		logging.Instance().Warnf("release stopped during '%s' after it reached the remote, so local changes were kept", step)
+	}
+	reportRemote(state.Journal)
+	logging.Instance().Info("fix the problem and run 'gog release -continue' to retry the remaining steps")
+
+	return output.WithCode(output.CodeGit, cause)
+}
+
+func (rc *ReleaseCommand) result(state *releaseState) *releaseResult {
+	result := &releaseResult{Releases: []releasedFeature{}}
+
+	for _, p := range state.Pending {
+		version := state.Versions[p.Feature.Ticket]
+
+		feature := releasedFeature{Ticket: p.Feature.Ticket, Component: p.Feature.Component, Version: version.String(), Tags: []string{}, Release: rc.published[p.Feature.Ticket]}
+		if !p.NoTag {
+			feature.Tags = releaseTags(version)
+		}
+		result.Releases = append(result.Releases, feature)
+	}
+
+	return result
+}
+
+func (rc *ReleaseCommand) loadState() (*git.Repository, *releaseState, error) {
+	if !releaseInProgress(git.DefaultBackend()) {
+		return nil, nil, output.Errorf(output.CodeUsage, "there is no release in progress for this repository")
+	}
+
+	state, err := loadReleaseState(git.DefaultBackend())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read release progress. %v", err)
+	}
+
+	rc.publish, rc.sign = state.Publish, state.Sign
+
+	r, err := git.NewRepository()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.CurrentBranch.Name != r.DefaultBranch.Name {
+		return nil, nil, output.Errorf(output.CodeUsage, "releases must be continued from %s (currently on %s)", r.DefaultBranch, r.CurrentBranch)
+	}
+
+	*r.FeatureBranch = *r.CurrentBranch
+
+	return r, state, nil
+}
+
+func (rc *ReleaseCommand) continueRelease() (*releaseResult, error) {
+	r, state, err := rc.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rc.openForge(r, state); err != nil {
+		return nil, err
+	}
+
+	logging.Instance().Infof("continuing release after completing steps %v", state.Journal.Completed)
+
+	return rc.runSteps(r, state, config.AppConfig().TagPrefix())
+}
+
+// abortRelease stops retrying the remaining steps of a release. what already reached the remote is left as it is
+func (rc *ReleaseCommand) abortRelease() (*releaseResult, error) {
+	r, state, err := rc.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	var remaining []string
+	for _, step := range rc.steps(r, state, config.AppConfig().TagPrefix()) {
+		if !state.Journal.Done(step.name) {
+			remaining = append(remaining, step.name)
+		}
+	}
+
+	if err := clearReleaseState(r.Backend()); err != nil {
+		return nil, fmt.Errorf("failed to clear release progress. %v", err)
+	}
+
+	reportRemote(state.Journal)
+	logging.Instance().Warnf("the remaining release steps %v were not run", remaining)
+	logging.Instance().Info("Successfully aborted the release!")
+
+	return rc.result(state), nil
+}
+
+// usePendingRelease selects the tag prefix and component p was finished with
+func usePendingRelease(p *models.PendingRelease, defaultPrefix string) error {
+	config.AppConfig().SetTagPrefix(defaultPrefix)
+	if p.Feature.CustomVersionPrefix != "" {
+		config.AppConfig().SetTagPrefix(p.Feature.CustomVersionPrefix)
+	}
+
+	if err := config.AppConfig().SetComponent(p.Feature.Component); err != nil {
+		return output.WithCode(output.CodeConfig, err)
+	}
+	return nil
+}
+
+func releaseTarget(p *models.PendingRelease) string {
+	if p.Feature.Component != "" {
+		return "component " + p.Feature.Component
+	}
+	return "the repository"
+}
+
+// releaseSteps returns the steps which release p as the version assigned to it in state, along with those which publish it once the tags have been pushed
+func (rc *ReleaseCommand) releaseSteps(r *git.Repository, state *releaseState, p *models.PendingRelease) ([]finishStep, []finishStep) {
+	var steps, publishSteps []finishStep
+	ticket := p.Feature.Ticket
+	previous, version := state.Previous[ticket], state.Versions[ticket]
+	publish := rc.publishes(p)
+
+	if !p.NoTag && (!p.NoChangelog || publish) {
+		steps = append(steps, finishStep{name: "write-changelog:" + ticket, run: func() error {
+			entry := changelog.NewChangelogEntry(p.Feature, r, version, p.Action == "MAJOR" || p.Action == "MINOR")
+			entry.Changes = p.Changes
+			entry.Previous = previous
+
+			if p.NoChangelog {
+				if _, err := entry.Build(); err != nil {
+					return output.Errorf(output.Code(err), "failed to build the changelog entry. %v", err)
+				}
+				state.Notes[ticket] = entry.String()
+				return nil
+			}
+
+			changelogLines, err := changelog.CreateChangeLogLines(entry)
+			if err != nil {
+				return output.Errorf(output.Code(err), "failed to update the changelog. %v", err)
+			}
+			state.Notes[ticket] = entry.String()
+
+			if err := changelog.WriteChangelogToFile(changelogLines); err != nil {
+				return fmt.Errorf("failed to write changelog entry. %v", err)
+			}
+			return nil
+		}})
+	}
+
+	if !p.NoTag {
+		steps = append(steps, finishStep{name: "bump-version-files:" + ticket, run: func() error {
+			return bumpVersionFiles(version, nil)
+		}})
+	}
+
+	steps = append(steps,
+		finishStep{name: "remove-pending:" + ticket, run: func() error {
+			if err := p.Remove(); err != nil {
+				return fmt.Errorf("failed to remove pending release for %s. %v", ticket, err)
+			}
+			return nil
+		}},
+		finishStep{name: "commit-release:" + ticket, run: func() error {
+			if err := r.StageChanges(); err != nil {
+				return fmt.Errorf("failed to stage release changes for %s. %v", ticket, err)
+			}
+
+			if err := r.CommitChanges(fmt.Sprintf("release %s: %s", version, releaseCommitMessage(p.Feature))); err != nil {
+				return fmt.Errorf("failed to commit release changes for %s. %v", ticket, err)
+			}
+			return nil
+		}},
+	)
+
+	if !p.NoTag {
+		steps = append(steps, finishStep{name: "create-tags:" + ticket, run: func() error {
+			for _, tag := range releaseTags(version) {
+				state.Journal.RecordTag(r, tag)
+			}
+
+			if err := p.Feature.CreateReleaseTags(r, version, state.Notes[ticket], tagSigning(p.Sign || rc.sign || config.AppConfig().SignTags())); err != nil {
+				return fmt.Errorf("failed to create release tags for %s. %v", ticket, err)
+			}
+			return nil
+		}})
+	}
+
+	if publish {
+		publishSteps = append(publishSteps, finishStep{name: "publish-release:" + ticket, remote: fmt.Sprintf("published forge release %s", version), retry: true, run: func() error {
+			release, err := publishRelease(rc.forge, version, state.Notes[ticket])
+			if err != nil {
+				return err
+			}
+
+			rc.published[ticket] = release
+			return nil
+		}})
+	}
+
+	return steps, publishSteps
+}
+
+func (rc *ReleaseCommand) Name() string {
+	return rc.name
+}
+
+func (rc *ReleaseCommand) Alias() string {
+	return ""
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/journal"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/semver"
+)
+
+// releaseState is a release which already reached the remote and still has steps left for 'gog release -continue'
+type releaseState struct {
+	Pending []*models.PendingRelease `json:"pending"`
+	Previous map[string]semver.Semver `json:"previous"`
+	Versions map[string]semver.Semver `json:"versions"`
+	Notes map[string]string `json:"notes,omitempty"`
+	Publish bool `json:"publish,omitempty"`
+	Sign bool `json:"sign,omitempty"`
+
+	Journal *journal.Journal `json:"journal"`
+}
+
+func releaseStatePath(b git.Backend) (string, error) {
+	gitDir, err := b.GitDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(gitDir, "gog", "release.json"), nil
+}
+
+func releaseInProgress(b git.Backend) bool {
+	path, err := releaseStatePath(b)
+	if err != nil {
+		return false
+	}
+
+	return common.PathExists(path)
+}
+
+func loadReleaseState(b git.Backend) (*releaseState, error) {
+	path, err := releaseStatePath(b)
+	if err != nil {
+		return nil, err
+	}
+
+	stateBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state *releaseState
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return nil, err
+	}
+
+	if state.Notes == nil {
+		state.Notes = map[string]string{}
+	}
+
+	logging.Instance().Debugf("loaded release state for %d pending release(s) from %s", len(state.Pending), path)
+
+	return state, nil
+}
+
+func (s *releaseState) save(b git.Backend) error {
+	path, err := releaseStatePath(b)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	stateBytes, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	logging.Instance().Debugf("saving release state (%d bytes) to %s", len(stateBytes), path)
+
+	return os.WriteFile(path, stateBytes, 0600)
+}
+
+func clearReleaseState(b git.Backend) error {
+	path, err := releaseStatePath(b)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
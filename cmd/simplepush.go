@@ -11,6 +11,7 @@ import (
 	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/git"
 	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
 	"sykesdev.ca/gog/internal/prompt"
 )
 
@@ -44,6 +45,12 @@ func gitLatestTestBuild(r *git.Repository) int {
 	return -1
 }
 
+type simplePushResult struct {
+	Branch string `json:"branch"`
+	Message string `json:"message"`
+	Commit string `json:"commit"`
+}
+
 func NewSimplePushCommand() *SimplePushCommand {
 	c := &SimplePushCommand{
 		name: "simple-push",
@@ -87,21 +94,23 @@ message
 	fmt.Println("\n-------================================-------")
 }
 
-func (c *SimplePushCommand) Run() error {
+func (c *SimplePushCommand) Run() (interface{}, error) {
 	r, err := git.NewRepository()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	GOGDir := common.GOGPath()
 
 	if common.PathExists(GOGDir + "/feature.json") {
 		logging.Instance().Warn("this project seems to already have an associated GOG feature file. It is recommended to use 'gog (p | push)' for feature code changes")
-		contResp := prompt.String("would you like to continue? [Y/N] ")
-
-		if strings.ToUpper(contResp) != "Y" {
+		proceed, err := prompt.Confirm("would you like to continue", prompt.NoDefault)
+		if err != nil {
+			return nil, err
+		}
+		if !proceed {
 			logging.Instance().Info("cancelling simple-push ...")
-			return nil
+			return nil, nil
 		}
 	}
 
@@ -113,26 +122,31 @@ func (c *SimplePushCommand) Run() error {
 	}
 
 	if err := r.StageChanges(); err != nil {
-		return fmt.Errorf("failed to stage changes for %s. %v", r.CurrentBranch, err)
+		return nil, output.Errorf(output.CodeGit, "failed to stage changes for %s. %v", r.CurrentBranch, err)
 	}
 
 	if err := r.CommitChanges(c.message); err != nil {
-		return fmt.Errorf("failed to commit changes for %s. %v", r.CurrentBranch, err)
+		return nil, output.Errorf(output.CodeGit, "failed to commit changes for %s. %v", r.CurrentBranch, err)
 	}
 	
 	if r.CurrentBranch.RemoteExists {
 		if err := r.PullChanges(); err != nil {
-			return fmt.Errorf("failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
+			return nil, output.Errorf(output.CodeGit, "failed to ensure %s is up to date with remote. %v", r.CurrentBranch, err)
 		}
 	}
 
 	if err := r.Push(); err != nil {
-		return fmt.Errorf("failed to push changes to remote for %s. %v", r.CurrentBranch, err)
+		return nil, output.Errorf(output.CodeGit, "failed to push changes to remote for %s. %v", r.CurrentBranch, err)
+	}
+
+	commit, err := r.HeadCommit()
+	if err != nil {
+		logging.Instance().Debugf("failed to read the pushed commit. %v", err)
 	}
 
 	logging.Instance().Info("Successfully pushed changes to remote (" + r.CurrentBranch.Name + ")!")
 	
-	return nil
+	return &simplePushResult{Branch: r.CurrentBranch.Name, Message: c.message, Commit: commit}, nil
 }
 
 func (c *SimplePushCommand) Name() string {
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/changelog"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/output"
+)
+
+type StatusCommand struct {
+	fs *flag.FlagSet
+
+	name string
+	alias string
+	json bool
+}
+
+type branchStatus struct {
+	Current string `json:"current"`
+	Default string `json:"default"`
+	Feature string `json:"feature,omitempty"`
+}
+
+type remoteStatus struct {
+	Branch string `json:"branch"`
+	Pushed bool `json:"pushed"`
+	Ahead int `json:"ahead"`
+	Behind int `json:"behind"`
+}
+
+type nextVersions struct {
+	Major string `json:"major"`
+	Minor string `json:"minor"`
+	Patch string `json:"patch"`
+}
+
+type statusReport struct {
+	Repository string `json:"repository"`
+	Feature *models.Feature `json:"feature"`
+	Component string `json:"component,omitempty"`
+	Changelog string `json:"changelog"`
+	Branches branchStatus `json:"branches"`
+	Remote remoteStatus `json:"remote"`
+	VersionPrefix string `json:"version_prefix"`
+	LastTag string `json:"last_tag"`
+	NextVersions nextVersions `json:"next_versions"`
+	UncommittedChanges bool `json:"uncommitted_changes"`
+	UntrackedFiles []string `json:"untracked_files"`
+	FinishInProgress bool `json:"finish_in_progress"`
+	PendingReleases []string `json:"pending_releases"`
+	Warnings []string `json:"warnings"`
+}
+
+func NewStatusCommand() *StatusCommand {
+	sc := &StatusCommand{
+		name: "status",
+		alias: "st",
+		fs: flag.NewFlagSet("status", flag.ContinueOnError),
+	}
+
+	sc.fs.BoolVar(&sc.json, "json", false, "prints the status as JSON")
+
+	sc.fs.Usage = sc.Help
+
+	return sc
+}
+
+func (sc *StatusCommand) Help() {
+	fmt.Printf(
+`Usage: %s (%s | %s) [-json] [-h] [-help]
+
+Shows the active GOG feature, the state of its branches against origin and what the next release would be.
+
+-------====== Status Arguments ======-------
+
+`, os.Args[0], sc.name, sc.alias)
+
+	sc.fs.PrintDefaults()
+
+	fmt.Println("\n-------================================-------")
+}
+
+func (sc *StatusCommand) Init(args []string) error {
+	return sc.fs.Parse(args)
+}
+
+func (sc *StatusCommand) Run() (interface{}, error) {
+	report, err := sc.report()
+	if err != nil {
+		return nil, err
+	}
+
+	if sc.json {
+		reportBytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode status. %v", err)
+		}
+
+		fmt.Fprintln(output.Stdout(), string(reportBytes))
+		return report, nil
+	}
+
+	sc.print(report)
+	return report, nil
+}
+
+func (sc *StatusCommand) report() (*statusReport, error) {
+	var feature *models.Feature
+	var warnings []string
+	component := ""
+	if common.PathExists(common.GOGPath() + "/feature.json") {
+		var err error
+		if feature, err = models.NewFeatureFromFile(); err != nil {
+			return nil, fmt.Errorf("failed to read feature from associated feature file. %v", err)
+		}
+
+		if feature.CustomVersionPrefix != "" {
+			config.AppConfig().SetTagPrefix(feature.CustomVersionPrefix)
+		}
+
+		selected, err := selectComponent(feature.Component)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		}
+		component = selected
+	}
+
+	r, err := git.NewRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &statusReport{
+		Repository: r.Name,
+		Feature: feature,
+		Component: component,
+		Changelog: changelog.File(),
+		Branches: branchStatus{Current: r.CurrentBranch.Name, Default: r.DefaultBranch.Name},
+		Remote: remoteStatus{Branch: r.CurrentBranch.Name, Pushed: r.CurrentBranch.RemoteExists},
+		VersionPrefix: config.AppConfig().TagPrefix(),
+		LastTag: r.LastTag.String(),
+		NextVersions: nextVersions{
+			Major: bumpReleaseVersion(r.LastTag, "MAJOR", "").String(),
+			Minor: bumpReleaseVersion(r.LastTag, "MINOR", "").String(),
+			Patch: bumpReleaseVersion(r.LastTag, "PATCH", "").String(),
+		},
+		UncommittedChanges: r.CurrentBranch.UncommittedChanges(),
+		FinishInProgress: finishInProgress(r.Backend()),
+		UntrackedFiles: []string{},
+		PendingReleases: []string{},
+		Warnings: append([]string{}, warnings...),
+	}
+
+	if feature != nil {
+		report.Branches.Feature = feature.Ticket
+
+		if r.CurrentBranch.Name != feature.Ticket {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("the feature file is for %s but the current branch is %s", feature.Ticket, r.CurrentBranch))
+		}
+	}
+
+	if r.VersionPrefix != config.AppConfig().TagPrefix() {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("feature version prefix specified does not match existing prefix for this git project ('%s' != '%s')", config.AppConfig().TagPrefix(), r.VersionPrefix))
+	}
+
+	if report.Remote.Pushed {
+		if report.Remote.Ahead, report.Remote.Behind, err = r.CurrentBranch.AheadBehind(); err != nil {
+			logging.Instance().Debugf("failed to compare %s with origin. %v", r.CurrentBranch, err)
+		}
+	}
+
+	if untracked, err := r.Backend().UntrackedFiles(); err == nil && untracked != nil {
+		report.UntrackedFiles = untracked
+	}
+
+	pending, err := models.PendingReleases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending releases. %v", err)
+	}
+	for _, p := range pending {
+		report.PendingReleases = append(report.PendingReleases, p.Feature.Ticket)
+	}
+
+	if report.FinishInProgress {
+		report.Warnings = append(report.Warnings, "a feature release is in progress. run 'gog finish -continue' or 'gog finish -abort'")
+	}
+
+	return report, nil
+}
+
+func (sc *StatusCommand) print(report *statusReport) {
+	w := output.Stdout()
+
+	fmt.Fprintf(w, "\n-------====== GOG Status for %s ======-------\n\n", report.Repository)
+
+	if report.Feature != nil {
+		fmt.Fprintf(w, "Feature:        %s %s\n", report.Feature.Ticket, report.Feature.Comment)
+		fmt.Fprintf(w, "Test builds:    %d\n", report.Feature.TestCount)
+		if report.Feature.IsStacked() {
+			fmt.Fprintf(w, "Stacked on:     %s\n", report.Feature.ParentFeature)
+		}
+	} else {
+		fmt.Fprintln(w, "Feature:        (none on this branch)")
+	}
+
+	fmt.Fprintf(w, "Current branch: %s\n", report.Branches.Current)
+	fmt.Fprintf(w, "Default branch: %s\n", report.Branches.Default)
+	if report.Branches.Feature != "" {
+		fmt.Fprintf(w, "Feature branch: %s\n", report.Branches.Feature)
+	}
+
+	if report.Remote.Pushed {
+		fmt.Fprintf(w, "Remote:         %d ahead, %d behind origin/%s\n", report.Remote.Ahead, report.Remote.Behind, report.Remote.Branch)
+	} else {
+		fmt.Fprintf(w, "Remote:         %s has not been pushed to origin\n", report.Remote.Branch)
+	}
+
+	changes := "clean"
+	if report.UncommittedChanges {
+		changes = "uncommitted changes"
+	}
+	if len(report.UntrackedFiles) > 0 {
+		changes = fmt.Sprintf("%s, %d untracked file(s)", changes, len(report.UntrackedFiles))
+	}
+	fmt.Fprintf(w, "Working tree:   %s\n", changes)
+
+	if report.Component != "" {
+		fmt.Fprintf(w, "\nComponent:      %s (%s)", report.Component, report.Changelog)
+	}
+	fmt.Fprintf(w, "\nVersion prefix: %s\n", report.VersionPrefix)
+	fmt.Fprintf(w, "Last release:   %s\n", report.LastTag)
+	fmt.Fprintf(w, "Next release:   major %s, minor %s, patch %s\n", report.NextVersions.Major, report.NextVersions.Minor, report.NextVersions.Patch)
+
+	if len(report.PendingReleases) > 0 {
+		fmt.Fprintf(w, "Pending:        %s (waiting for 'gog release')\n", strings.Join(report.PendingReleases, ", "))
+	}
+
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(w, "\nWARNING: %s", warning)
+	}
+	if len(report.Warnings) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "\n-------================================-------")
+}
+
+func (sc *StatusCommand) Name() string {
+	return sc.name
+}
+
+func (sc *StatusCommand) Alias() string {
+	return sc.alias
+}
@@ -8,6 +8,7 @@ import (
 	"runtime"
 
 	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
 	"sykesdev.ca/gog/internal/update"
 )
 
@@ -18,6 +19,11 @@ type UpdateSelfCommand struct {
 	tag string
 }
 
+type updateResult struct {
+	PreviousVersion string `json:"previous_version"`
+	Version string `json:"version"`
+}
+
 func NewUpdateSelfCommand() *UpdateSelfCommand {
 	usc := &UpdateSelfCommand{
 		name: "update",
@@ -46,24 +52,24 @@ func (usc *UpdateSelfCommand) Init(args []string) error {
 	return usc.fs.Parse(args)
 }
 
-func (usc *UpdateSelfCommand) Run() error {
+func (usc *UpdateSelfCommand) Run() (interface{}, error) {
 	if runtime.GOOS == "windows" {
-		return errors.New("NOT IMPLEMENTED - currently the in-place upgrade feature will not work on Windows")
+		return nil, output.WithCode(output.CodeUpdate, errors.New("NOT IMPLEMENTED - currently the in-place upgrade feature will not work on Windows"))
 	}
 
 	logging.Instance().Info("Performing in-place upgrade for GOG ...")
 
 	u, err := update.NewUpdater(usc.tag)
 	if err != nil {
-		return err
+		return nil, output.WithCode(output.CodeUpdate, err)
 	}
 
 	if err := u.Update(); err != nil {
-		return err
+		return nil, output.WithCode(output.CodeUpdate, err)
 	}
 
 	logging.Instance().Infof("Successfully updated GOG from %s to %s", u.CurrentVersion(), u.UpdateVersion())
-	return nil
+	return &updateResult{PreviousVersion: u.CurrentVersion().String(), Version: u.UpdateVersion().String()}, nil
 }
 
 func (usc *UpdateSelfCommand) Name() string {
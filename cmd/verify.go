@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
+)
+
+type VerifyCommand struct {
+	fs *flag.FlagSet
+
+	name string
+	since string
+	component string
+	tags []string
+}
+
+type tagVerification struct {
+	Tag string `json:"tag"`
+	Signed bool `json:"signed"`
+	git.Signature
+}
+
+type verifyResult struct {
+	Tags []tagVerification `json:"tags"`
+	Verified int `json:"verified"`
+	Failed []string `json:"failed"`
+}
+
+func NewVerifyCommand() *VerifyCommand {
+	vc := &VerifyCommand{
+		name: "verify",
+		fs: flag.NewFlagSet("verify", flag.ContinueOnError),
+	}
+
+	vc.fs.StringVar(&vc.since, "since", "", "only verifies the release tags newer than this one (eg. those made since signing was adopted)")
+	vc.fs.StringVar(&vc.component, "component", "", "verifies the release tags of the named monorepo component")
+
+	vc.fs.Usage = vc.Help
+
+	return vc
+}
+
+func (vc *VerifyCommand) Help() {
+	fmt.Printf(
+`Usage: %s %s [-since <tag>] [-component name] [tag ...] [-h] [-help]
+
+-------====== Verify Arguments ======-------
+
+tag
+	the release tags to verify. defaults to every release tag merged into the default branch
+
+Checks the GPG or SSH signature of release tags. unsigned tags and bad signatures are reported as failures.
+SSH signatures are checked against git's gpg.ssh.allowedSignersFile.
+
+------================================------
+
+`, os.Args[0], vc.name)
+
+	vc.fs.PrintDefaults()
+
+	fmt.Println("\n-------================================-------")
+}
+
+func (vc *VerifyCommand) Init(args []string) error {
+	if err := vc.fs.Parse(args); err != nil {
+		return err
+	}
+	vc.tags = vc.fs.Args()
+
+	if vc.since != "" && len(vc.tags) > 0 {
+		return fmt.Errorf("invalid usage of verify. -since cannot be used with a list of tags (re-run with -h for full usage details)")
+	}
+
+	return nil
+}
+
+func (vc *VerifyCommand) Run() (interface{}, error) {
+	if vc.component != "" {
+		if _, err := selectComponent(vc.component); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := git.NewRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := vc.releaseTags(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &verifyResult{Tags: []tagVerification{}, Failed: []string{}}
+	for _, tag := range tags {
+		signature, err := r.VerifyTag(tag)
+		if err != nil {
+			return nil, output.Errorf(output.CodeGit, "failed to verify tag %s. %v", tag, err)
+		}
+
+		verification := tagVerification{Tag: tag, Signed: signature.Signed(), Signature: signature}
+		result.Tags = append(result.Tags, verification)
+
+		switch {
+		case !signature.Signed():
+			result.Failed = append(result.Failed, tag)
+			fmt.Fprintf(output.Stdout(), "%-24s UNSIGNED\n", tag)
+		case !signature.Valid:
+			result.Failed = append(result.Failed, tag)
+			fmt.Fprintf(output.Stdout(), "%-24s BAD %s signature. %s\n", tag, signature.Format, signature.Detail)
+		default:
+			result.Verified++
+			fmt.Fprintf(output.Stdout(), "%-24s OK  %s signature by %s\n", tag, signature.Format, signature.Signer)
+		}
+	}
+
+	if len(result.Failed) > 0 {
+		return result, output.Errorf(output.CodeVerify, "%d of %d release tag(s) are unsigned or have a bad signature", len(result.Failed), len(result.Tags))
+	}
+
+	logging.Instance().Infof("verified the signatures of %d release tag(s)", result.Verified)
+
+	return result, nil
+}
+
+// releaseTags are the tags given on the command line, or the release tags of the repository newer than -since
+func (vc *VerifyCommand) releaseTags(r *git.Repository) ([]string, error) {
+	if len(vc.tags) > 0 {
+		return vc.tags, nil
+	}
+
+	versionTags, err := r.VersionTags()
+	if err != nil {
+		return nil, output.Errorf(output.CodeGit, "failed to read the release tags. %v", err)
+	}
+
+	first := 0
+	if vc.since != "" {
+		first = -1
+		for i, t := range versionTags {
+			if t.Name == vc.since {
+				first = i + 1
+			}
+		}
+		if first < 0 {
+			return nil, output.Errorf(output.CodeUsage, "'%s' is not a release tag of this repository", vc.since)
+		}
+	}
+
+	var tags []string
+	for _, t := range versionTags[first:] {
+		tags = append(tags, t.Name)
+	}
+
+	if len(tags) == 0 {
+		logging.Instance().Info("there are no release tags to verify")
+	}
+
+	return tags, nil
+}
+
+func (vc *VerifyCommand) Name() string {
+	return vc.name
+}
+
+func (vc *VerifyCommand) Alias() string {
+	return ""
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/journal"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/semver"
+	"sykesdev.ca/gog/internal/versionfile"
+)
+
+// backupVersionFiles records the version files of the active component or repository so they are restored on rollback
+func backupVersionFiles(j *journal.Journal) error {
+	for _, f := range config.AppConfig().ReleaseVersionFiles() {
+		if err := j.Backup(f.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bumpVersionFiles writes version (without its prefix) into the version files of the active component or repository. with a plan the edits are only recorded
+func bumpVersionFiles(version semver.Semver, plan *releasePlan) error {
+	files := config.AppConfig().ReleaseVersionFiles()
+	if len(files) == 0 {
+		return nil
+	}
+
+	projectRoot, err := common.GitProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	edits, err := versionfile.Plan(projectRoot, files, version.NoPrefix())
+	if err != nil {
+		return err
+	}
+
+	for _, e := range edits {
+		if !e.Changed() {
+			logging.Instance().Debugf("version file %s already holds %s", e.Path, version.NoPrefix())
+			continue
+		}
+
+		if plan != nil {
+			plan.files = append(plan.files, "update " + e.Path)
+			plan.versionFiles += e.Diff()
+		}
+	}
+
+	if plan != nil {
+		return nil
+	}
+
+	if err := versionfile.Write(projectRoot, edits); err != nil {
+		return fmt.Errorf("failed to bump version files. %v", err)
+	}
+
+	logging.Instance().Infof("set version %s in %d version file(s)", version.NoPrefix(), len(edits))
+	return nil
+}
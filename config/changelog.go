@@ -0,0 +1,123 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"sykesdev.ca/gog/internal/common"
+)
+
+// Keep a Changelog sections
+const (
+	Added = "Added"
+	Changed = "Changed"
+	Deprecated = "Deprecated"
+	Removed = "Removed"
+	Fixed = "Fixed"
+	Security = "Security"
+)
+
+// placeholders of the changelog link templates
+const (
+	TicketPlaceholder = "{ticket}"
+	HashPlaceholder = "{hash}"
+	ShortHashPlaceholder = "{short_hash}"
+	FromPlaceholder = "{from}"
+	ToPlaceholder = "{to}"
+)
+
+// ChangelogLinks are url templates for linking tickets, commits and releases in the changelog. empty templates are not linked
+type ChangelogLinks struct {
+	// Ticket is the issue tracker page of a ticket, eg. 'https://example.atlassian.net/browse/{ticket}'
+	Ticket string `yaml:"ticket"`
+	// Commit is the forge page of a commit, eg. 'https://github.com/owner/repo/commit/{hash}'
+	Commit string `yaml:"commit"`
+	// Compare is the forge page comparing two tags, eg. 'https://github.com/owner/repo/compare/{from}...{to}'
+	Compare string `yaml:"compare"`
+}
+
+// ChangelogTemplates are text/template templates replacing the builtin layout of the changelog. empty templates use the builtin one
+type ChangelogTemplates struct {
+	// Header is written at the top of a new changelog
+	Header string `yaml:"header"`
+	// Entry renders a release, and must start with its '## ' heading
+	Entry string `yaml:"entry"`
+}
+
+// Categories are the Keep a Changelog sections, in the order they are written to a release
+var Categories = []string{Added, Changed, Deprecated, Removed, Fixed, Security}
+
+// builtinCategories map conventional commit types to the section their changes are listed under
+var builtinCategories = map[string]string{
+	"feat": Added,
+	"fix": Fixed,
+	"perf": Changed,
+	"refactor": Changed,
+	"deprecate": Deprecated,
+	"remove": Removed,
+	"security": Security,
+}
+
+// ChangelogCategories returns the commit type to section mapping, with changelog.categories in config taking precedence over the builtin one
+func (c *Configuration) ChangelogCategories() map[string]string {
+	categories := map[string]string{}
+	for commitType, category := range builtinCategories {
+		categories[commitType] = category
+	}
+	for commitType, category := range c.Changelog.Categories {
+		categories[strings.ToLower(commitType)] = category
+	}
+	return categories
+}
+
+// ChangelogCategory resolves a commit type (eg. 'fix') or a section name (eg. 'fixed') to the section changes are listed under
+func (c *Configuration) ChangelogCategory(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", false
+	}
+
+	categories := c.ChangelogCategories()
+	if category, ok := categories[name]; ok && category != "" {
+		return category, true
+	}
+
+	for _, category := range categories {
+		if strings.EqualFold(category, name) {
+			return category, true
+		}
+	}
+	for _, category := range Categories {
+		if strings.EqualFold(category, name) {
+			return category, true
+		}
+	}
+
+	return "", false
+}
+
+// ChangelogTypes are the commit types and sections accepted by ChangelogCategory, for usage messages
+func (c *Configuration) ChangelogTypes() []string {
+	var types []string
+	for commitType, category := range c.ChangelogCategories() {
+		if category != "" {
+			types = append(types, commitType)
+		}
+	}
+	sort.Strings(types)
+
+	for _, category := range Categories {
+		if name := strings.ToLower(category); !common.StringInSlice(types, name) {
+			types = append(types, name)
+		}
+	}
+	return types
+}
+
+func (c *Configuration) ChangelogLinks() ChangelogLinks {
+	return c.Changelog.Links
+}
+
+func (c *Configuration) ChangelogTemplates() ChangelogTemplates {
+	return c.Changelog.Templates
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// VersionPlaceholder marks where the version goes in a component tag pattern, eg. 'api/v{version}'
+const VersionPlaceholder = "{version}"
+
+var tagPrefixRegexp = regexp.MustCompile(`^[A-Za-z0-9/._-]*$`)
+
+// Component is an independently versioned part of a monorepo with its own tags and changelog
+type Component struct {
+	Name string `yaml:"-"`
+	Path string `yaml:"path"`
+	TagPattern string `yaml:"tag_pattern"`
+	Changelog string `yaml:"changelog"`
+	VersionFiles []VersionFile `yaml:"version_files"`
+}
+
+// TagPrefix is the tag pattern without the version, eg. 'api/v' for 'api/v{version}'. it defaults to '<name>/v'
+func (c Component) TagPrefix() string {
+	if c.TagPattern == "" {
+		return c.Name + "/v"
+	}
+	return strings.TrimSuffix(c.TagPattern, VersionPlaceholder)
+}
+
+// ChangelogPath is the changelog of the component relative to the project root. it defaults to CHANGELOG.md in the component path
+func (c Component) ChangelogPath() string {
+	if c.Changelog == "" {
+		return filepath.ToSlash(filepath.Join(c.Path, "CHANGELOG.md"))
+	}
+	return filepath.ToSlash(filepath.Clean(c.Changelog))
+}
+
+// Owns reports if path, relative to the project root, is inside the component
+func (c Component) Owns(path string) bool {
+	dir := filepath.ToSlash(filepath.Clean(c.Path))
+	return path == dir || strings.HasPrefix(path, dir + "/")
+}
+
+// Components returns the components declared in config, sorted by name
+func (c *Configuration) Components() []Component {
+	var components []Component
+	for name, component := range c.DeclaredComponents {
+		component.Name = name
+		components = append(components, component)
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+
+	return components
+}
+
+func (c *Configuration) Component(name string) (Component, error) {
+	component, ok := c.DeclaredComponents[name]
+	if !ok {
+		var names []string
+		for _, known := range c.Components() {
+			names = append(names, known.Name)
+		}
+		return Component{}, fmt.Errorf("unknown component '%s'. declared components are: %s", name, strings.Join(names, ", "))
+	}
+
+	component.Name = name
+	return component, nil
+}
+
+// SetComponent versions, tags and records changes for the named component instead of the whole repository. an empty name selects the repository
+func (c *Configuration) SetComponent(name string) error {
+	if name == "" {
+		c.component = ""
+		return nil
+	}
+
+	component, err := c.Component(name)
+	if err != nil {
+		return err
+	}
+
+	c.component = name
+	c.SetTagPrefix(component.TagPrefix())
+	return nil
+}
+
+// ActiveComponent returns the selected component, if any
+func (c *Configuration) ActiveComponent() (Component, bool) {
+	if c.component == "" {
+		return Component{}, false
+	}
+
+	component, err := c.Component(c.component)
+	return component, err == nil
+}
+
+// ComponentForTag returns the component which tag was created for, if any
+func (c *Configuration) ComponentForTag(tag string) (Component, bool) {
+	var match Component
+	found := false
+	for _, component := range c.Components() {
+		if strings.HasPrefix(tag, component.TagPrefix()) && (!found || len(component.TagPrefix()) > len(match.TagPrefix())) {
+			match, found = component, true
+		}
+	}
+	return match, found
+}
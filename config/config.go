@@ -3,8 +3,10 @@ package config
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"sync"
 
 	"gopkg.in/yaml.v2"
@@ -20,13 +22,46 @@ logging:
   level: "INFO"
 application:
   tag_prefix: "v"
+tickets:
+  # tracker used to validate ticket keys for new features (jira, github, linear or a custom tracker below)
+  tracker: "jira"
+  # allow any valid branch name as the ticket instead of a tracker key
+  free_form: false
+  trackers:
+    jira:
+      pattern: "^[A-Za-z]+-[0-9]+$"
+      example: "JIRA-0023"
+      # optionally restrict tickets to the listed project keys (eg. [ "ABC", "OPS" ])
+      projects: []
 `
 
+// RepoConfigFile is the optional, committed configuration at the root of a project
+const RepoConfigFile = ".gog.yml"
+
+var builtinTrackers = map[string]Tracker{
+	"jira": {Pattern: `^[A-Za-z]+-[0-9]+$`, Example: "JIRA-0023"},
+	"github": {Pattern: `^(GH-)?[0-9]+$`, Example: "GH-123"},
+	"linear": {Pattern: `^[A-Za-z]+-[0-9]+$`, Example: "ENG-123"},
+}
+
 var (
 	once sync.Once
 	instance Configuration
+	loadErr error
 )
 
+type Tracker struct {
+	Pattern string `yaml:"pattern"`
+	Example string `yaml:"example"`
+	Projects []string `yaml:"projects"`
+}
+
+type Tickets struct {
+	Tracker string `yaml:"tracker"`
+	FreeForm bool `yaml:"free_form"`
+	Trackers map[string]Tracker `yaml:"trackers"`
+}
+
 type Configuration struct {
 	Logging struct {
 		Level string `yaml:"level"`
@@ -35,57 +70,194 @@ type Configuration struct {
 	Application struct {
 		TagPrefix string `yaml:"tag_prefix"`
 	} `yaml:"application"`
+
+	Tickets Tickets `yaml:"tickets"`
+
+	DeclaredComponents map[string]Component `yaml:"components"`
+
+	VersionFiles []VersionFile `yaml:"version_files"`
+
+	Changelog struct {
+		Categories map[string]string `yaml:"categories"`
+		Links ChangelogLinks `yaml:"links"`
+		Templates ChangelogTemplates `yaml:"templates"`
+	} `yaml:"changelog"`
+
+	Forge struct {
+		Provider string `yaml:"provider"`
+		BaseURL string `yaml:"base_url"`
+		Token string `yaml:"token"`
+		Repository string `yaml:"repository"`
+	} `yaml:"forge"`
+
+	Release struct {
+		Publish bool `yaml:"publish"`
+		Assets []string `yaml:"assets"`
+		Sign bool `yaml:"sign"`
+		SigningFormat string `yaml:"signing_format"`
+		SigningKey string `yaml:"signing_key"`
+		TagMessage string `yaml:"tag_message"`
+	} `yaml:"release"`
+
+	Update struct {
+		Provider string `yaml:"provider"`
+		BaseURL string `yaml:"base_url"`
+		Repository string `yaml:"repository"`
+	} `yaml:"update"`
+
+	values map[string]interface{}
+	origins map[string]Origin
+
+	forgeURLTrusted bool
+
+	component string
 }
 
-func AppConfig() *Configuration {
+// Load reads the configuration once. when it cannot be read, the defaults are used in its place and the error is returned,
+// so that the commands which repair the configuration can still run
+func Load() error {
 	once.Do(func ()  {
 		instance = Configuration{}
 
-		err := instance.load()
-		if err != nil {
-			panic("failed to load app configuration instance\n" + err.Error())
+		if loadErr = instance.load(); loadErr != nil {
+			instance = Configuration{}
+			if err := instance.loadDefaults(); err != nil {
+				loadErr = fmt.Errorf("%v. failed to load the default configuration. %v", loadErr, err)
+			}
 		}
 	})
 
+	return loadErr
+}
+
+func AppConfig() *Configuration {
+	Load()
 	return &instance
 }
 
-func (c *Configuration) load() error {
+func UserConfigPath() (string, error) {
 	configDir, err := os.UserConfigDir()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	appConfigPath := configDir + "/gog/config.yml"
+	return configDir + "/gog/config.yml", nil
+}
+
+func RepoConfigPath() (string, error) {
+	projectRoot, err := common.GitProjectRoot()
+	if err != nil {
+		return "", err
+	}
 
-	var f *os.File
+	return projectRoot + "/" + RepoConfigFile, nil
+}
+
+// loadUser reads the user configuration, pre-loading it with defaults when it does not exist yet
+func loadUser(appConfigPath string) ([]byte, error) {
 	if !common.PathExists(appConfigPath) {
-		if err := os.MkdirAll(configDir + "/gog/", 0755); err != nil { return err }
-		f, err = os.Create(appConfigPath)
-		if err != nil {
-			return err
+		if err := os.MkdirAll(filepath.Dir(appConfigPath), 0755); err != nil {
+			return nil, err
 		}
-		
-		// pre-load with defaults
-		_, err = f.Write([]byte(defaults))
+
+		if err := os.WriteFile(appConfigPath, []byte(defaults), 0644); err != nil {
+			return nil, err
+		}
+
+		return []byte(defaults), nil
+	}
+
+	f, err := os.Open(appConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// load merges defaults, the user config, the project's .gog.yml and GOG_* environment variables (in increasing precedence)
+func (c *Configuration) load() error {
+	defaultLayer, err := parseLayer([]byte(defaults), Origin{Source: OriginDefault})
+	if err != nil {
+		return err
+	}
+	layers := []layer{defaultLayer}
+
+	appConfigPath, err := UserConfigPath()
+	if err != nil {
+		return err
+	}
+
+	userBytes, err := loadUser(appConfigPath)
+	if err != nil {
+		return err
+	}
+
+	userLayer, err := parseLayer(userBytes, Origin{Source: OriginUser, Path: appConfigPath})
+	if err != nil {
+		return fmt.Errorf("failed to parse user configuration at %s. %v", appConfigPath, err)
+	}
+	layers = append(layers, userLayer)
+
+	if repoConfigPath, err := RepoConfigPath(); err != nil {
+		logging.Instance().Debugf("not in a git project, skipping repo configuration. %v", err)
+	} else if common.PathExists(repoConfigPath) {
+		repoBytes, err := os.ReadFile(repoConfigPath)
 		if err != nil {
 			return err
 		}
-	} else {
-		f, err = os.Open(appConfigPath)
+
+		repoLayer, err := parseLayer(repoBytes, Origin{Source: OriginRepo, Path: repoConfigPath})
 		if err != nil {
+			return fmt.Errorf("failed to parse repo configuration at %s. %v", repoConfigPath, err)
+		}
+		repoLayer.dropUserOnly()
+		layers = append(layers, repoLayer)
+
+		logging.Instance().Debugf("loaded repo configuration from %s", repoConfigPath)
+	}
+
+	known, _ := merge(layers)
+	layers = append(layers, envLayer(known))
+
+	return c.apply(layers)
+}
+
+// loadDefaults sets up the configuration from the defaults alone
+func (c *Configuration) loadDefaults() error {
+	defaultLayer, err := parseLayer([]byte(defaults), Origin{Source: OriginDefault})
+	if err != nil {
+		return err
+	}
+	return c.apply([]layer{defaultLayer})
+}
+
+// apply merges layers into the configuration. values of the wrong kind are reported along with the file or variable they came from
+func (c *Configuration) apply(layers []layer) error {
+	for _, l := range layers {
+		if err := l.checkKinds(); err != nil {
 			return err
 		}
 	}
-	defer f.Close()
 
-	buf := bytes.NewBuffer(nil)
-	if _, err := io.Copy(buf, f); err != nil {
+	c.values, c.origins = merge(layers)
+	c.forgeURLTrusted = forgeURLTrusted(layers)
+
+	merged, err := yaml.Marshal(unflatten(c.values))
+	if err != nil {
 		return err
 	}
 
-	err = yaml.Unmarshal(buf.Bytes(), &c)
-	return err
+	if err := yaml.Unmarshal(merged, c); err != nil {
+		return fmt.Errorf("failed to read configuration. %v", err)
+	}
+	return nil
 }
 
 func (c *Configuration) TagPrefix() string {
@@ -107,4 +279,110 @@ func (c *Configuration) SetLogLevel(level string) error {
 
 	c.Logging.Level = level
 	return nil
-}
\ No newline at end of file
+}
+
+func (c *Configuration) TicketTracker() string {
+	if c.Tickets.Tracker == "" {
+		return "jira"
+	}
+	return c.Tickets.Tracker
+}
+
+func (c *Configuration) FreeFormTickets() bool {
+	return c.Tickets.FreeForm
+}
+
+// Tracker returns the ticket tracker selected in config, falling back to the builtin definition for any unset fields
+func (c *Configuration) Tracker() (Tracker, error) {
+	name := c.TicketTracker()
+
+	tracker, configured := c.Tickets.Trackers[name]
+	builtin, isBuiltin := builtinTrackers[name]
+	if !configured && !isBuiltin {
+		return Tracker{}, fmt.Errorf("unknown ticket tracker '%s'. define a pattern for it under tickets.trackers", name)
+	}
+
+	if tracker.Pattern == "" {
+		tracker.Pattern = builtin.Pattern
+	}
+	if tracker.Example == "" {
+		tracker.Example = builtin.Example
+	}
+
+	return tracker, nil
+}
+
+// ForgeProvider selects github, gitlab or gitea. empty detects the provider from the origin url
+func (c *Configuration) ForgeProvider() string {
+	return c.Forge.Provider
+}
+
+// ForgeBaseURL is the web root of a self-hosted forge. empty uses the public instance of the provider
+func (c *Configuration) ForgeBaseURL() string {
+	return c.Forge.BaseURL
+}
+
+// ForgeToken is the API token of the forge. it is withheld from a forge.base_url which only the committed repo config sets,
+// since that would send the user's token to a host chosen by the repository
+func (c *Configuration) ForgeToken() string {
+	if c.Forge.Token != "" && !c.forgeURLTrusted {
+		logging.Instance().Warnf("not sending the forge token to %s, which is only set in the repo config (%s). set forge.base_url in the user config to trust it", c.Forge.BaseURL, c.origins["forge.base_url"].Path)
+		return ""
+	}
+	return c.Forge.Token
+}
+
+// ForgeRepository overrides the owner/name of the repository otherwise read from the origin url
+func (c *Configuration) ForgeRepository() string {
+	return c.Forge.Repository
+}
+
+// PublishRelease reports if finish and release should publish a release with the changelog notes on the forge after tagging
+func (c *Configuration) PublishRelease() bool {
+	return c.Release.Publish
+}
+
+// SignTags reports if release tags are always signed, rather than only with -sign
+func (c *Configuration) SignTags() bool {
+	return c.Release.Sign
+}
+
+// SigningFormat is the format release tags are signed in (gpg or ssh), or empty for git's gpg.format
+func (c *Configuration) SigningFormat() string {
+	return c.Release.SigningFormat
+}
+
+// SigningKey is the key release tags are signed with, or empty for git's user.signingkey
+func (c *Configuration) SigningKey() string {
+	return c.Release.SigningKey
+}
+
+// TagMessage is the text/template for release tag messages, or empty for the builtin one
+func (c *Configuration) TagMessage() string {
+	return c.Release.TagMessage
+}
+
+// ReleaseAssets are glob patterns, relative to the project root, of files to attach to published releases
+func (c *Configuration) ReleaseAssets() []string {
+	return c.Release.Assets
+}
+
+// UpdateProvider is the forge GOG updates itself from
+func (c *Configuration) UpdateProvider() string {
+	if c.Update.Provider == "" {
+		return "github"
+	}
+	return c.Update.Provider
+}
+
+func (c *Configuration) UpdateBaseURL() string {
+	return c.Update.BaseURL
+}
+
+// UpdateRepository is the owner/name of the repository GOG releases are published to
+func (c *Configuration) UpdateRepository() string {
+	if c.Update.Repository == "" {
+		return "SystemFiles/GOG"
+	}
+	return c.Update.Repository
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// useConfig points the user config at user and runs the test in a git project with repo as its .gog.yml. nil skips a file
+func useConfig(t *testing.T, user, repo map[string]interface{}) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("GIT_CEILING_DIRECTORIES", dir)
+
+	// overrides from the environment running the tests would take precedence over every file
+	for _, variable := range os.Environ() {
+		if name := strings.SplitN(variable, "=", 2)[0]; strings.HasPrefix(name, "GOG_") {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+
+	write := func(path string, values map[string]interface{}) {
+		content, err := yaml.Marshal(unflatten(values))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if user != nil {
+		write(filepath.Join(dir, "config", "gog", "config.yml"), user)
+	}
+
+	project := filepath.Join(dir, "project")
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "init", "-q", project).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed. %v %s", err, out)
+	}
+	if repo != nil {
+		write(filepath.Join(project, RepoConfigFile), repo)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		key string
+		user map[string]interface{}
+		repo map[string]interface{}
+		env map[string]string
+		want interface{}
+		origin string
+	}{
+		{"default", "application.tag_prefix", map[string]interface{}{}, nil, nil, "v", OriginDefault},
+		{"defaults written to a new user config", "application.tag_prefix", nil, nil, nil, "v", OriginUser},
+		{"user over default", "application.tag_prefix", map[string]interface{}{"application.tag_prefix": "u"}, nil, nil, "u", OriginUser},
+		{"repo over user", "application.tag_prefix", map[string]interface{}{"application.tag_prefix": "u"}, map[string]interface{}{"application.tag_prefix": "r"}, nil, "r", OriginRepo},
+		{"env over repo", "application.tag_prefix", map[string]interface{}{"application.tag_prefix": "u"}, map[string]interface{}{"application.tag_prefix": "r"}, map[string]string{"GOG_APPLICATION_TAG_PREFIX": "e"}, "e", OriginEnv},
+		{"repo over default", "tickets.free_form", nil, map[string]interface{}{"tickets.free_form": true}, nil, true, OriginRepo},
+		{"env values are parsed", "tickets.free_form", map[string]interface{}{"tickets.free_form": true}, nil, map[string]string{"GOG_TICKETS_FREE_FORM": "false"}, false, OriginEnv},
+		{"env alias", "logging.level", nil, map[string]interface{}{"logging.level": "INFO"}, map[string]string{"GOG_LOG_LEVEL": "DEBUG"}, "DEBUG", OriginEnv},
+		{"user only keys ignore the repo", "update.base_url", map[string]interface{}{"update.base_url": "https://u.example.com"}, map[string]interface{}{"update.base_url": "https://r.example.com"}, nil, "https://u.example.com", OriginUser},
+		{"user only keys from env", "update.base_url", nil, map[string]interface{}{"update.base_url": "https://r.example.com"}, map[string]string{"GOG_UPDATE_BASE_URL": "https://e.example.com"}, "https://e.example.com", OriginEnv},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, tt.user, tt.repo)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			c := &Configuration{}
+			if err := c.load(); err != nil {
+				t.Fatalf("load failed. %v", err)
+			}
+
+			if got := c.values[tt.key]; got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+			}
+			if got := c.origins[tt.key].Source; got != tt.origin {
+				t.Errorf("%s came from %s, want %s", tt.key, got, tt.origin)
+			}
+		})
+	}
+}
+
+func TestLoadRepoOnlyUserOnlyKey(t *testing.T) {
+	useConfig(t, nil, map[string]interface{}{"update.repository": "evil/gog"})
+
+	c := &Configuration{}
+	if err := c.load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.values["update.repository"]; ok || c.UpdateRepository() == "evil/gog" {
+		t.Errorf("update.repository = %v, want the repo config ignored", c.values["update.repository"])
+	}
+}
+
+func TestLoadWrongKind(t *testing.T) {
+	useConfig(t, nil, map[string]interface{}{"tickets.free_form": "maybe"})
+
+	if err := (&Configuration{}).load(); err == nil {
+		t.Error("load should report a bool key holding another kind of value")
+	}
+}
+
+func TestForgeURLTrusted(t *testing.T) {
+	tests := []struct {
+		name string
+		user map[string]interface{}
+		repo map[string]interface{}
+		token string
+		trusted bool
+	}{
+		{"no base url", nil, nil, "secret", true},
+		{"user base url", map[string]interface{}{"forge.base_url": "https://git.example.com"}, nil, "secret", true},
+		{"repo base url", nil, map[string]interface{}{"forge.base_url": "https://evil.example.com"}, "secret", false},
+		{"repo base url matching the user", map[string]interface{}{"forge.base_url": "https://git.example.com"}, map[string]interface{}{"forge.base_url": "https://git.example.com"}, "secret", true},
+		{"repo base url and token", nil, map[string]interface{}{"forge.base_url": "https://git.example.com", "forge.token": "t"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, tt.user, tt.repo)
+			if tt.token != "" {
+				t.Setenv("GOG_FORGE_TOKEN", tt.token)
+			}
+
+			c := &Configuration{}
+			if err := c.load(); err != nil {
+				t.Fatal(err)
+			}
+			if c.forgeURLTrusted != tt.trusted || (c.ForgeToken() != "") != tt.trusted {
+				t.Errorf("trusted = %t with token '%s', want %t", c.forgeURLTrusted, c.ForgeToken(), tt.trusted)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+const (
+	ScopeUser = "user"
+	ScopeRepo = "repo"
+)
+
+func ScopePath(scope string) (string, error) {
+	switch scope {
+	case ScopeUser:
+		return UserConfigPath()
+	case ScopeRepo:
+		path, err := RepoConfigPath()
+		if err != nil {
+			return "", fmt.Errorf("cannot use repo scope outside of a git project. %v", err)
+		}
+		return path, nil
+	default:
+		return "", fmt.Errorf("unknown config scope '%s'. must be one of %s, %s", scope, ScopeUser, ScopeRepo)
+	}
+}
+
+// Document is a single config file which can be edited without losing its comments or layout
+type Document struct {
+	Path string
+	root *yamlv3.Node
+}
+
+func LoadDocument(path string) (*Document, error) {
+	d := &Document{Path: path}
+
+	if !common.PathExists(path) {
+		d.root = &yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode}}}
+		return d, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// leading blank lines cause yaml.v3 to attach the first comment to the wrong node
+	content = bytes.TrimLeft(content, " \t\r\n")
+
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s. %v", path, err)
+	}
+	if root.Kind == 0 {
+		root = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode}}}
+	}
+	if root.Content[0].Kind != yamlv3.MappingNode {
+		return nil, fmt.Errorf("failed to parse %s. top level of the config must be a mapping", path)
+	}
+	d.root = &root
+
+	return d, nil
+}
+
+func LoadScope(scope string) (*Document, error) {
+	path, err := ScopePath(scope)
+	if err != nil {
+		return nil, err
+	}
+	return LoadDocument(path)
+}
+
+func mappingValue(mapping *yamlv3.Node, key string) (int, *yamlv3.Node) {
+	for i := 0; i + 1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i, mapping.Content[i + 1]
+		}
+	}
+	return -1, nil
+}
+
+// Values returns the flattened values set in this document
+func (d *Document) Values() (map[string]interface{}, error) {
+	var raw interface{}
+	if err := d.root.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	content, err := yamlv3.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := parseLayer(content, Origin{Path: d.Path})
+	if err != nil {
+		return nil, err
+	}
+	return l.values, nil
+}
+
+func (d *Document) Get(key string) (interface{}, bool) {
+	values, err := d.Values()
+	if err != nil {
+		return nil, false
+	}
+
+	value, ok := values[key]
+	return value, ok
+}
+
+// Set validates and writes value at the dotted key, creating intermediate mappings as needed
+func (d *Document) Set(key string, value interface{}) error {
+	if err := Validate(key, value); err != nil {
+		return err
+	}
+
+	node := d.root.Content[0]
+	parts := strings.Split(key, ".")
+
+	for i, part := range parts {
+		_, next := mappingValue(node, part)
+
+		if i == len(parts) - 1 {
+			var encoded yamlv3.Node
+			if err := encoded.Encode(value); err != nil {
+				return err
+			}
+			if encoded.Kind == yamlv3.SequenceNode {
+				encoded.Style = yamlv3.FlowStyle
+			}
+
+			if next == nil {
+				node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: part}, &encoded)
+				break
+			}
+
+			encoded.HeadComment, encoded.LineComment, encoded.FootComment = next.HeadComment, next.LineComment, next.FootComment
+			*next = encoded
+			break
+		}
+
+		if next == nil {
+			next = &yamlv3.Node{Kind: yamlv3.MappingNode}
+			node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: part}, next)
+		}
+		if next.Kind != yamlv3.MappingNode {
+			return fmt.Errorf("cannot set %s since %s is not a section", key, strings.Join(parts[:i + 1], "."))
+		}
+		node = next
+	}
+
+	logging.Instance().Debugf("set %s = %v in %s", key, value, d.Path)
+
+	return nil
+}
+
+// Unset removes the dotted key from the document. it reports false if the key was not set
+func (d *Document) Unset(key string) bool {
+	node := d.root.Content[0]
+	parts := strings.Split(key, ".")
+
+	for i, part := range parts {
+		index, next := mappingValue(node, part)
+		if next == nil {
+			return false
+		}
+
+		if i == len(parts) - 1 {
+			node.Content = append(node.Content[:index], node.Content[index + 2:]...)
+			break
+		}
+
+		if next.Kind != yamlv3.MappingNode {
+			return false
+		}
+		node = next
+	}
+
+	logging.Instance().Debugf("unset %s in %s", key, d.Path)
+
+	return true
+}
+
+// Validate checks every value in the document against the config schema
+func (d *Document) Validate() []error {
+	values, err := d.Values()
+	if err != nil {
+		return []error{err}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		if err := Validate(key, values[key]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (d *Document) Save() error {
+	buf := bytes.NewBuffer(nil)
+
+	encoder := yamlv3.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(d.root); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.Path), 0755); err != nil {
+		return err
+	}
+
+	logging.Instance().Debugf("saving config (%d bytes) to %s", buf.Len(), d.Path)
+
+	return os.WriteFile(d.Path, buf.Bytes(), 0644)
+}
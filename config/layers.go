@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+const (
+	OriginDefault = "default"
+	OriginUser = "user"
+	OriginRepo = "repo"
+	OriginEnv = "env"
+)
+
+// envAliases maps environment variables kept for backwards compatibility to their config keys
+var envAliases = map[string]string{
+	"GOG_LOG_LEVEL": "logging.level",
+}
+
+type Origin struct {
+	Source string `json:"source"`
+	Path string `json:"path,omitempty"`
+}
+
+func (o Origin) String() string {
+	if o.Path == "" {
+		return o.Source
+	}
+	return fmt.Sprintf("%s (%s)", o.Source, o.Path)
+}
+
+type Setting struct {
+	Key string `json:"key"`
+	Value interface{} `json:"value"`
+	Origin Origin `json:"origin"`
+}
+
+// layer holds flattened configuration values (eg. 'logging.level') along with where each of them came from
+type layer struct {
+	values map[string]interface{}
+	origins map[string]Origin
+}
+
+func newLayer() layer {
+	return layer{values: make(map[string]interface{}), origins: make(map[string]Origin)}
+}
+
+func parseLayer(content []byte, origin Origin) (layer, error) {
+	l := newLayer()
+
+	var raw map[interface{}]interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return l, err
+	}
+
+	flatten("", raw, l.values)
+	for key := range l.values {
+		l.origins[key] = origin
+	}
+
+	return l, nil
+}
+
+func flatten(prefix string, in interface{}, out map[string]interface{}) {
+	nested, ok := in.(map[interface{}]interface{})
+	if !ok {
+		if in != nil {
+			out[prefix] = in
+		}
+		return
+	}
+
+	for k, v := range nested {
+		key := fmt.Sprint(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flatten(key, v, out)
+	}
+}
+
+func unflatten(values map[string]interface{}) map[string]interface{} {
+	nested := make(map[string]interface{})
+
+	for key, value := range values {
+		parts := strings.Split(key, ".")
+
+		m := nested
+		for _, part := range parts[:len(parts) - 1] {
+			next, ok := m[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				m[part] = next
+			}
+			m = next
+		}
+		m[parts[len(parts) - 1]] = value
+	}
+
+	return nested
+}
+
+// dropUserOnly removes the keys which the repo config may not set, eg. update.base_url which would let a cloned repository
+// choose where gog update downloads its binary from
+func (l layer) dropUserOnly() {
+	for key := range l.values {
+		if UserOnly(key) {
+			logging.Instance().Warnf("ignoring %s in %s. it can only be set in the user config or %s", key, l.origins[key], EnvName(key))
+			delete(l.values, key)
+			delete(l.origins, key)
+		}
+	}
+}
+
+// checkKinds reports the first bool or list key holding a value of another kind, eg. 'maybe' for a bool
+func (l layer) checkKinds() error {
+	keys := make([]string, 0, len(l.values))
+	for key := range l.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// string keys also take other scalars, eg. a numeric token, which are read into the string
+		s, err := lookupSchema(key)
+		if err != nil || s.kind == KindString {
+			continue
+		}
+		if err := s.checkKind(key, l.values[key]); err != nil {
+			return fmt.Errorf("%v (from %s)", err, l.origins[key])
+		}
+	}
+	return nil
+}
+
+func merge(layers []layer) (map[string]interface{}, map[string]Origin) {
+	values := make(map[string]interface{})
+	origins := make(map[string]Origin)
+
+	for _, l := range layers {
+		for key, value := range l.values {
+			values[key] = value
+			origins[key] = l.origins[key]
+		}
+	}
+
+	return values, origins
+}
+
+// forgeURLTrusted reports if the forge token may be sent to forge.base_url. a url from the repo config is only trusted when the
+// user or environment configure the same url, or when the token comes from the repo config too
+func forgeURLTrusted(layers []layer) bool {
+	values, origins := merge(layers)
+	url, ok := values["forge.base_url"]
+	if !ok || origins["forge.base_url"].Source != OriginRepo || origins["forge.token"].Source == OriginRepo {
+		return true
+	}
+
+	for _, l := range layers {
+		if l.origins["forge.base_url"].Source != OriginRepo && l.values["forge.base_url"] == url {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvName returns the environment variable which overrides a config key, eg. GOG_APPLICATION_TAG_PREFIX for 'application.tag_prefix'
+func EnvName(key string) string {
+	return "GOG_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+func parseEnvValue(raw string) interface{} {
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+		return raw
+	}
+	return value
+}
+
+// envLayer reads overrides for the known config keys from GOG_* environment variables
+func envLayer(known map[string]interface{}) layer {
+	l := newLayer()
+
+	for alias, key := range envAliases {
+		if raw, ok := os.LookupEnv(alias); ok {
+			l.values[key] = parseEnvValue(raw)
+			l.origins[key] = Origin{Source: OriginEnv, Path: alias}
+		}
+	}
+
+	keys := make(map[string]bool)
+	for key := range known {
+		keys[key] = true
+	}
+	for _, s := range schema {
+		if !strings.Contains(s.key, "*") {
+			keys[s.key] = true
+		}
+	}
+
+	for key := range keys {
+		name := EnvName(key)
+		if raw, ok := os.LookupEnv(name); ok {
+			l.values[key] = parseEnvValue(raw)
+			l.origins[key] = Origin{Source: OriginEnv, Path: name}
+		}
+	}
+
+	return l
+}
+
+// ValidateEnv checks the values of the GOG_* environment variables overriding config keys
+func ValidateEnv() []error {
+	env := envLayer(map[string]interface{}{})
+
+	keys := make([]string, 0, len(env.values))
+	for key := range env.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		if err := Validate(key, env.values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%v (from %s)", err, env.origins[key]))
+		}
+	}
+	return errs
+}
+
+// Settings lists every effective configuration value, sorted by key, along with its origin
+func (c *Configuration) Settings() []Setting {
+	settings := make([]Setting, 0, len(c.values))
+	for key, value := range c.values {
+		settings = append(settings, Setting{Key: key, Value: value, Origin: c.origins[key]})
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+
+	return settings
+}
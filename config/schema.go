@@ -0,0 +1,342 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/common/constants"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+const (
+	KindString = "string"
+	KindBool = "bool"
+	KindList = "list"
+)
+
+// keySchema describes a configuration key. '*' in a key matches any single segment, eg. a tracker name
+type keySchema struct {
+	key string
+	kind string
+	validate func(value interface{}) error
+	// secret keys hold credentials, which are never saved to the committed repo config or printed
+	secret bool
+	// userOnly keys are ignored in the committed repo config, since a cloned repository must not be able to set them
+	userOnly bool
+}
+
+var schema = []keySchema{
+	{key: "logging.level", kind: KindString, validate: validateLogLevel},
+	{key: "application.tag_prefix", kind: KindString, validate: validateTagPrefix},
+	{key: "tickets.tracker", kind: KindString, validate: validateNotEmpty},
+	{key: "tickets.free_form", kind: KindBool},
+	{key: "tickets.trackers.*.pattern", kind: KindString, validate: validatePattern},
+	{key: "tickets.trackers.*.example", kind: KindString},
+	{key: "tickets.trackers.*.projects", kind: KindList},
+	{key: "forge.provider", kind: KindString, validate: validateProvider},
+	{key: "forge.base_url", kind: KindString, validate: validateURL},
+	{key: "forge.token", kind: KindString, secret: true},
+	{key: "forge.repository", kind: KindString, validate: validateRepository},
+	{key: "components.*.path", kind: KindString, validate: validateComponentPath},
+	{key: "components.*.tag_pattern", kind: KindString, validate: validateTagPattern},
+	{key: "components.*.changelog", kind: KindString},
+	{key: "components.*.version_files", kind: KindList, validate: validateVersionFiles},
+	{key: "version_files", kind: KindList, validate: validateVersionFiles},
+	{key: "changelog.categories.*", kind: KindString},
+	{key: "changelog.links.ticket", kind: KindString, validate: validateLinkTemplate(TicketPlaceholder)},
+	{key: "changelog.links.commit", kind: KindString, validate: validateLinkTemplate(HashPlaceholder, ShortHashPlaceholder)},
+	{key: "changelog.links.compare", kind: KindString, validate: validateLinkTemplate(FromPlaceholder, ToPlaceholder)},
+	{key: "changelog.templates.header", kind: KindString, validate: validateTemplate},
+	{key: "changelog.templates.entry", kind: KindString, validate: validateTemplate},
+	{key: "release.publish", kind: KindBool},
+	{key: "release.assets", kind: KindList, validate: validateGlobs},
+	{key: "release.sign", kind: KindBool},
+	{key: "release.signing_format", kind: KindString, validate: validateSigningFormat},
+	{key: "release.signing_key", kind: KindString},
+	{key: "release.tag_message", kind: KindString, validate: validateTemplate},
+	{key: "update.provider", kind: KindString, validate: validateProvider, userOnly: true},
+	{key: "update.base_url", kind: KindString, validate: validateURL, userOnly: true},
+	{key: "update.repository", kind: KindString, validate: validateRepository, userOnly: true},
+}
+
+func (s keySchema) checkKind(key string, value interface{}) error {
+	switch s.kind {
+	case KindBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("invalid value for %s. expected true or false, got '%v'", key, value)
+		}
+	case KindList:
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Errorf("invalid value for %s. expected a list, got '%v'", key, value)
+		}
+	default:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("invalid value for %s. expected a string, got '%v'", key, value)
+		}
+	}
+	return nil
+}
+
+func (s keySchema) matches(key string) bool {
+	schemaParts, keyParts := strings.Split(s.key, "."), strings.Split(key, ".")
+	if len(schemaParts) != len(keyParts) {
+		return false
+	}
+
+	for i := range schemaParts {
+		if schemaParts[i] != "*" && schemaParts[i] != keyParts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupSchema(key string) (keySchema, error) {
+	for _, s := range schema {
+		if s.matches(key) {
+			return s, nil
+		}
+	}
+	return keySchema{}, fmt.Errorf("unknown config key '%s'", key)
+}
+
+// redacted is shown in place of the value of a secret key
+const redacted = "********"
+
+// IsSecret reports if key holds a credential, eg. forge.token
+func IsSecret(key string) bool {
+	s, err := lookupSchema(key)
+	return err == nil && s.secret
+}
+
+// UserOnly reports if key is only read from the user config and the environment, eg. where gog update downloads itself from
+func UserOnly(key string) bool {
+	s, err := lookupSchema(key)
+	return err == nil && s.userOnly
+}
+
+// Redact hides the value of a secret key so that it can be printed
+func Redact(key string, value interface{}) interface{} {
+	if IsSecret(key) && value != nil && value != "" {
+		return redacted
+	}
+	return value
+}
+
+// ParseValue converts a value given on the command line into the type expected for key
+func ParseValue(key, raw string) (interface{}, error) {
+	s, err := lookupSchema(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.kind {
+	case KindBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s. expected true or false", key)
+		}
+		return b, nil
+	case KindList:
+		if strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			var list []interface{}
+			if err := yaml.Unmarshal([]byte(raw), &list); err != nil {
+				return nil, fmt.Errorf("invalid list for %s. %v", key, err)
+			}
+			return list, nil
+		}
+
+		list := []interface{}{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	default:
+		return raw, nil
+	}
+}
+
+// Validate checks that key is a known configuration key and that value is acceptable for it
+func Validate(key string, value interface{}) error {
+	s, err := lookupSchema(key)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkKind(key, value); err != nil {
+		return err
+	}
+
+	if s.validate != nil {
+		if err := s.validate(value); err != nil {
+			return fmt.Errorf("invalid value for %s. %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+func validateLogLevel(value interface{}) error {
+	if !common.StringInSlice(logging.SeverityLevels, strings.ToUpper(value.(string))) {
+		return fmt.Errorf("invalid severity level '%s'. must be one of %s", value, strings.Join(logging.SeverityLevels, ", "))
+	}
+	return nil
+}
+
+func validateTagPrefix(value interface{}) error {
+	if matched, _ := regexp.MatchString(constants.VersionPrefixRegexp + "$", value.(string)); !matched {
+		return fmt.Errorf("invalid version prefix '%s'. prefixes may only contain letters optionally followed by a '-'", value)
+	}
+	return nil
+}
+
+func validateNotEmpty(value interface{}) error {
+	if value.(string) == "" {
+		return fmt.Errorf("value cannot be empty")
+	}
+	return nil
+}
+
+func validateProvider(value interface{}) error {
+	if value.(string) != "" && !common.StringInSlice([]string{"github", "gitlab", "gitea"}, value.(string)) {
+		return fmt.Errorf("unknown forge provider '%s'. must be one of github, gitlab, gitea", value)
+	}
+	return nil
+}
+
+func validateURL(value interface{}) error {
+	if value.(string) == "" {
+		return nil
+	}
+
+	u, err := url.Parse(value.(string))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url '%s'. expected an http(s) url such as https://git.example.com", value)
+	}
+	return nil
+}
+
+func validateSigningFormat(value interface{}) error {
+	if value.(string) != "" && !common.StringInSlice([]string{"gpg", "ssh"}, value.(string)) {
+		return fmt.Errorf("unknown signing format '%s'. must be one of gpg, ssh", value)
+	}
+	return nil
+}
+
+// validateTemplate checks the syntax of a text/template. fields are checked when the template is rendered, since the data is not known here
+func validateTemplate(value interface{}) error {
+	if _, err := template.New("").Parse(value.(string)); err != nil {
+		return fmt.Errorf("invalid template. %v", err)
+	}
+	return nil
+}
+
+// validateLinkTemplate checks for a url using at least one of placeholders. the placeholders must be replaced before parsing, since '{' is not valid in a host
+func validateLinkTemplate(placeholders ...string) func(value interface{}) error {
+	return func(value interface{}) error {
+		if value.(string) == "" {
+			return nil
+		}
+
+		used := false
+		expanded := value.(string)
+		for _, p := range placeholders {
+			used = used || strings.Contains(expanded, p)
+			expanded = strings.ReplaceAll(expanded, p, "x")
+		}
+
+		if !used {
+			return fmt.Errorf("invalid link template '%s'. expected the url to contain %s", value, strings.Join(placeholders, " or "))
+		}
+		return validateURL(expanded)
+	}
+}
+
+func validateRepository(value interface{}) error {
+	if value.(string) == "" {
+		return nil
+	}
+
+	if parts := strings.Split(value.(string), "/"); len(parts) < 2 || common.StringInSlice(parts, "") {
+		return fmt.Errorf("invalid repository '%s'. expected the form owner/name", value)
+	}
+	return nil
+}
+
+func validatePattern(value interface{}) error {
+	if _, err := regexp.Compile(value.(string)); err != nil {
+		return fmt.Errorf("invalid regular expression. %v", err)
+	}
+	return nil
+}
+
+func validateComponentPath(value interface{}) error {
+	path := filepath.ToSlash(filepath.Clean(value.(string)))
+	if value.(string) == "" || filepath.IsAbs(value.(string)) || path == ".." || strings.HasPrefix(path, "../") {
+		return fmt.Errorf("invalid component path '%s'. expected a directory relative to the project root", value)
+	}
+	return nil
+}
+
+func validateTagPattern(value interface{}) error {
+	if value.(string) == "" {
+		return nil
+	}
+
+	if !strings.HasSuffix(value.(string), VersionPlaceholder) || strings.Count(value.(string), VersionPlaceholder) != 1 {
+		return fmt.Errorf("invalid tag pattern '%s'. the pattern must end with %s, eg. 'api/v%s'", value, VersionPlaceholder, VersionPlaceholder)
+	}
+
+	if prefix := strings.TrimSuffix(value.(string), VersionPlaceholder); !tagPrefixRegexp.MatchString(prefix) {
+		return fmt.Errorf("invalid tag pattern '%s'. the text before %s may only contain letters, digits, '/', '-', '_' and '.'", value, VersionPlaceholder)
+	}
+	return nil
+}
+
+func validateVersionFiles(value interface{}) error {
+	files, err := parseVersionFiles(value.([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateGlobs(value interface{}) error {
+	for _, pattern := range value.([]interface{}) {
+		if _, err := filepath.Match(fmt.Sprint(pattern), ""); err != nil {
+			return fmt.Errorf("invalid glob pattern '%v'. %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks every effective value along with the selected ticket tracker
+func (c *Configuration) Validate() []error {
+	var errs []error
+	for _, s := range c.Settings() {
+		if err := Validate(s.Key, s.Value); err != nil {
+			errs = append(errs, fmt.Errorf("%v (from %s)", err, s.Origin))
+		}
+	}
+
+	if _, err := c.Tracker(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// VersionFile is a file holding the project version which finish rewrites with each release. the version is found with
+// a regular expression (its first capture group, or the whole match) or, for JSON, YAML and TOML files, a dotted key path
+type VersionFile struct {
+	Path string `yaml:"path" json:"path"`
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+}
+
+// FileFormat is the configured format, or the one implied by the file extension
+func (f VersionFile) FileFormat() string {
+	if f.Format != "" {
+		return strings.ToLower(f.Format)
+	}
+
+	switch strings.ToLower(filepath.Ext(f.Path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return ""
+}
+
+func (f VersionFile) Validate() error {
+	if err := validateComponentPath(f.Path); err != nil {
+		return fmt.Errorf("invalid version file path '%s'. expected a file relative to the project root", f.Path)
+	}
+
+	if (f.Pattern == "") == (f.Key == "") {
+		return fmt.Errorf("version file %s must set exactly one of pattern or key", f.Path)
+	}
+
+	if f.Pattern != "" {
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern for version file %s. %v", f.Path, err)
+		}
+		return nil
+	}
+
+	switch f.FileFormat() {
+	case FormatJSON, FormatYAML, FormatTOML:
+		return nil
+	case "":
+		return fmt.Errorf("cannot tell the format of version file %s from its extension. set format to json, yaml or toml", f.Path)
+	}
+	return fmt.Errorf("unknown format '%s' for version file %s. must be one of json, yaml, toml", f.Format, f.Path)
+}
+
+func parseVersionFiles(raw []interface{}) ([]VersionFile, error) {
+	out, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []VersionFile
+	if err := yaml.UnmarshalStrict(out, &files); err != nil {
+		return nil, fmt.Errorf("expected a list of version files with path and pattern or key. %v", err)
+	}
+	return files, nil
+}
+
+// ReleaseVersionFiles are the version files of the active component, or of the repository when there is none
+func (c *Configuration) ReleaseVersionFiles() []VersionFile {
+	if component, ok := c.ActiveComponent(); ok {
+		return component.VersionFiles
+	}
+	return c.VersionFiles
+}
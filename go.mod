@@ -5,6 +5,7 @@ go 1.17
 require (
 	github.com/google/go-github/v43 v43.0.0
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -1,13 +1,13 @@
 package changelog
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"regexp"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"sykesdev.ca/gog/config"
 	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/git"
 	"sykesdev.ca/gog/internal/logging"
@@ -15,92 +15,108 @@ import (
 	"sykesdev.ca/gog/internal/semver"
 )
 
-const fileHeader = `
-# Changelog
-
-All notable changes to this project will be documented in this file.
-
-The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/)
-and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
-
-
-`
+// File is the changelog being written, relative to the project root. this is the changelog of the active component in a monorepo
+func File() string {
+	if component, ok := config.AppConfig().ActiveComponent(); ok {
+		return component.ChangelogPath()
+	}
+	return "CHANGELOG.md"
+}
 
-func CreateChangeLogLines(entry *ChangelogEntry) ([]string, error) {
+func path() (string, error) {
 	projectRoot, err := common.GitProjectRoot()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	logging.Instance().Debugf("projectRoot: %s", projectRoot)
-	logging.Instance().Debugf("opening changelog file if exists from %s", projectRoot + "/CHANGELOG.md")
 
-	f, err := os.OpenFile(projectRoot + "/CHANGELOG.md", os.O_RDWR|os.O_CREATE, 0644)
+	return filepath.Join(projectRoot, filepath.FromSlash(File())), nil
+}
+
+// Read parses the changelog file, which is empty when it does not exist yet
+func Read() (*Changelog, error) {
+	changelogPath, err := path()
 	if err != nil {
-		return []string{}, err
+		return nil, err
 	}
-	defer f.Close()
 
-	logging.Instance().Debug("creating the changelog entry ...")
+	logging.Instance().Debugf("opening changelog file if exists from %s", changelogPath)
 
-	var changelogLines []string
-	scanner := bufio.NewScanner(bufio.NewReader(f))
-	for scanner.Scan() {
-		logging.Instance().Debugf("adding line from CHANGELOG.md: %s", scanner.Text())
-		changelogLines = append(changelogLines, scanner.Text())
+	content, err := os.ReadFile(changelogPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
 	}
 
-	latestFeatIndex := 0
-	versionLine := regexp.MustCompile(`^(#){2}(\ ){1}(\[)`)
-	for i, line := range changelogLines {
-		logging.Instance().Debugf("processing changelog line: %s", line)
-		if matched := versionLine.MatchString(line); matched {
-			logging.Instance().Debugf("matched version line: %s", line)
-			latestFeatIndex = i
-			break
-		}
+	if strings.TrimSpace(string(content)) == "" {
+		return newChangelog()
 	}
 
-	existingChanges := []string{}
-	if latestFeatIndex != 0 {
-		existingChanges = changelogLines[latestFeatIndex:]
-	}
+	c := Parse(string(content))
 
-	logging.Instance().Debugf("captured existing changes with line-count: %d", len(existingChanges))
+	logging.Instance().Debugf("parsed %d release(s) and %d link(s) from %s", len(c.Releases), len(c.Links), File())
+
+	return c, nil
+}
+
+// CreateChangeLogLines adds the entry to the changelog below any Unreleased block, whose content moves into the entry.
+// the rest of the changelog is left as it is, including headings written by older versions of GOG (see Upgrade)
+func CreateChangeLogLines(entry *ChangelogEntry) ([]string, error) {
+	c, err := Read()
+	if err != nil {
+		return []string{}, err
+	}
 	logging.Instance().Debug("inserting changelog lines for new release ...")
 
-	changelogLines = append([]string{}, fileHeader)
-	changelogLines = append(changelogLines, entry.String())
-	changelogLines = append(changelogLines, existingChanges...)
+	release, err := entry.Build()
+	if err != nil {
+		return []string{}, err
+	}
+
+	previous := c.Latest()
+	c.Add(release)
+
+	from := ""
+	if entry.Previous != (semver.Semver{}) {
+		from = entry.Previous.String()
+	} else if previous != nil {
+		from = previous.Version
+	}
+	if release.Version == entry.tag() {
+		c.linkCompare(entry.tag(), from)
+	}
 
 	logging.Instance().Debug("finished inserting changelog lines for new release")
 
-	return changelogLines, nil
+	return c.Lines(), nil
 }
 
 func WriteChangelogToFile(lines []string) error {
-	projectRoot, err := common.GitProjectRoot()
+	changelogPath, err := path()
 	if err != nil {
 		return err
 	}
 
-	logging.Instance().Debugf("projectRoot: %s", projectRoot)
-	logging.Instance().Debugf("opening CHANGELOG for writing from: %s", projectRoot + "/CHANGELOG.md")
+	logging.Instance().Debugf("opening CHANGELOG for writing from: %s", changelogPath)
+
+	if err := os.MkdirAll(filepath.Dir(changelogPath), 0755); err != nil {
+		return err
+	}
 
-	changelogFile, err := os.Create(projectRoot + "/CHANGELOG.md")
+	changelogFile, err := os.Create(changelogPath)
 	if err != nil {
 		return err
 	}
 	defer changelogFile.Close()
 	
-	logging.Instance().Debugf("writing %d lines to %s/CHANGELOG.md", len(lines), projectRoot)
+	logging.Instance().Debugf("writing %d lines to %s", len(lines), changelogPath)
 
 	_, err = changelogFile.Write([]byte(strings.Join(lines, "\n")))
 	if err != nil {
 		return err
 	}
 
-	logging.Instance().Debugf("completed write to %s/CHANGELOG.md", projectRoot)
+	logging.Instance().Debugf("completed write to %s", changelogPath)
 
 	return nil
 }
@@ -109,51 +125,127 @@ type ChangelogEntry struct {
 	Repository *git.Repository
 	Feature *models.Feature
 	Version semver.Semver
+	// Tag is the release tag, when it is not the version with the configured tag prefix (eg. for tags made before the prefix changed)
+	Tag string
+	// Added lists uncategorized changes under Added rather than Changed
 	Added bool
+	Date time.Time
+
+	// Previous is the version this release follows, if any. it defaults to the latest release in the changelog
+	Previous semver.Semver
+
+	// Changes are used instead of the feature branch commits when set, eg. for a feature already merged through a pull request
+	Changes []models.Change
+
+	release *Release
 }
 
 func NewChangelogEntry(feature *models.Feature, repo *git.Repository, version semver.Semver, added bool) (*ChangelogEntry) {
 	logging.Instance().Debugf("created new changelog entry with value: (%s, %s, %s, %t)", feature, repo, version, added)
-	return &ChangelogEntry{ Feature: feature, Repository: repo, Version: version, Added: added }
+	return &ChangelogEntry{ Feature: feature, Repository: repo, Version: version, Added: added, Date: time.Now().UTC() }
 }
 
-func (e *ChangelogEntry) Lines() []string {
+// Build renders the entry with the entry template. it is built once so that every rendering of the entry is identical (eg. in CHANGELOG.md and release notes),
+// and includes anything moved in from the Unreleased block once the entry has been added to the changelog
+func (e *ChangelogEntry) Build() (*Release, error) {
+	if e.release != nil {
+		return e.release, nil
+	}
+
 	logging.Instance().Debug("generating lines for configured changelog entry")
 
-	currentTime := time.Now().UTC()
-	formattedTimeString := fmt.Sprintf("%d-%d-%d %d:%d:%d",
-		currentTime.Year(),
-		currentTime.Month(),
-		currentTime.Day(),
-		currentTime.Hour(),
-		currentTime.Minute(),
-		currentTime.Second())
-	var lines []string
-	lines = append(lines, fmt.Sprintf("## [ %s ] - %s", e.Version, formattedTimeString))
-	lines = append(lines, fmt.Sprintf("\n> %s %s", e.Feature.Jira, e.Feature.Comment))
+	if e.Changes == nil {
+		changes, err := e.Feature.Changes(e.Repository)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get feature changes from git. try pushing a change first. %v", err)
+		}
+		e.Changes = append([]models.Change{}, changes...)
+	}
 
+	logging.Instance().Debugf("captured the following changes for this feature release: %v", e.Changes)
+
+	release, err := renderRelease(e.Data())
+	if err != nil {
+		return nil, err
+	}
+	e.release = release
+
+	return e.release, nil
+}
+
+// Data is what the entry template is rendered with
+func (e *ChangelogEntry) Data() EntryData {
+	data := EntryData{
+		Version: e.tag(),
+		Date: e.Date.Format("2006-01-02"),
+		Time: e.Date,
+		Ticket: e.Feature.Ticket,
+		Comment: e.Feature.Comment,
+		Summary: strings.TrimSpace(linkTicket(e.Feature.Ticket, e.Feature.Ticket) + " " + e.Feature.Comment),
+		TicketURL: ticketURL(e.Feature.Ticket),
+		Sections: []SectionData{},
+		Changes: []ChangeData{},
+	}
+
+	if e.Previous != (semver.Semver{}) {
+		data.Previous = e.Previous.String()
+		data.CompareURL, _ = compareLink(data.Previous, data.Version)
+	}
+
+	fallback := config.Changed
 	if e.Added {
-		lines = append(lines, "\n### Added\n")
-	} else {
-		lines = append(lines, "\n### Changed\n")
+		fallback = config.Added
 	}
 
-	changes, err := e.Feature.Changes(e.Repository)
-	if err != nil {
-		logging.Instance().Fatalf("failed to get feature changes from git. try pushing a change first. %v", err)
+	// sections are in Keep a Changelog order followed by any custom categories
+	order := append([]string{}, config.Categories...)
+	byCategory := map[string][]ChangeData{}
+	for _, c := range e.Changes {
+		change := ChangeData{
+			Category: c.Category,
+			Hash: c.Hash,
+			ShortHash: c.ShortHash,
+			Author: c.Author,
+			Subject: c.Subject,
+			Title: linkTicket(c.Subject, e.Feature.Ticket),
+			URL: commitURL(c),
+			Reference: commitReference(c),
+		}
+		if change.Category == "" {
+			change.Category = fallback
+		}
+
+		if _, ok := byCategory[change.Category]; !ok && !common.StringInSlice(order, change.Category) {
+			order = append(order, change.Category)
+		}
+		byCategory[change.Category] = append(byCategory[change.Category], change)
+		data.Changes = append(data.Changes, change)
 	}
 
-	logging.Instance().Debugf("captured the following changes for this feature release: %v", changes)
+	for _, category := range order {
+		if changes, ok := byCategory[category]; ok {
+			data.Sections = append(data.Sections, SectionData{Name: category, Changes: changes})
+		}
+	}
 
-	lines = append(lines, changes...)
-	lines = append(lines, "\n\n")
+	return data
+}
 
-	logging.Instance().Debugf("changelog entry has %d lines", len(lines))
+func (e *ChangelogEntry) tag() string {
+	if e.Tag != "" {
+		return e.Tag
+	}
+	return e.Version.String()
+}
 
-	return lines
+// Lines are the lines of the entry as it was built. an entry is empty until Build succeeds, so callers Build it first to handle any error
+func (e *ChangelogEntry) Lines() []string {
+	if e.release == nil {
+		return []string{}
+	}
+	return e.release.Lines()
 }
 
 func (e *ChangelogEntry) String() string {
 	return strings.Join(e.Lines(), "\n")
 }
-
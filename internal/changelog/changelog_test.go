@@ -0,0 +1,67 @@
+package changelog
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/semver"
+)
+
+// TestMain keeps the tests away from the user's GOG config and any git project around the working directory
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gog-changelog-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("GIT_CEILING_DIRECTORIES", dir)
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestChangelogEntryBuild(t *testing.T) {
+	feature := &models.Feature{Ticket: "ABC-1", Comment: "login"}
+	entry := NewChangelogEntry(feature, nil, semver.MustParse("1.3.0"), true)
+	entry.Changes = []models.Change{{ShortHash: "abc1234", Subject: "ABC-1 feat: login form", Category: "Added"}}
+
+	if got := entry.String(); got != "" {
+		t.Errorf("String() before Build = %q, want an empty entry", got)
+	}
+
+	release, err := entry.Build()
+	if err != nil {
+		t.Fatalf("Build failed. %v", err)
+	}
+
+	if release.Version != "v1.3.0" || release.Section("Added") == nil {
+		t.Errorf("unexpected release %+v", release)
+	}
+	if got := entry.String(); !strings.Contains(got, "login form") || got != release.String() {
+		t.Errorf("String() = %q, want the built release", got)
+	}
+}
+
+func TestChangelogEntryBuildError(t *testing.T) {
+	// the feature branch does not exist, so its changes cannot be read
+	r, err := git.NewRepositoryWithBackend(git.NewMemoryBackend("/work/app", "main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.FeatureBranch = r.NewBranch("ABC-1")
+
+	entry := NewChangelogEntry(&models.Feature{Ticket: "ABC-1"}, r, semver.MustParse("1.3.0"), false)
+	if _, err := entry.Build(); err == nil {
+		t.Error("Build should return the error reading the feature changes")
+	}
+	if got := entry.String(); got != "" {
+		t.Errorf("String() after a failed Build = %q, want an empty entry", got)
+	}
+}
@@ -0,0 +1,425 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const header = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// legacyHeader is the header written by older versions of GOG, which Upgrade replaces with header
+const legacyHeader = `
+# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/)
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// Unreleased is the label of the block collecting changes which are not part of a release yet
+const Unreleased = "Unreleased"
+
+var (
+	releaseHeading = regexp.MustCompile(`^##\s+\[\s*([^\]]+?)\s*\](?:\s*-\s*(.*?))?\s*$`)
+	// headings without brackets are only releases when labelled with a version (eg. 'v1.2.3' or 'api-1.2') or Unreleased, so '## Guidelines' stays a heading
+	plainReleaseHeading = regexp.MustCompile(`^##\s+([^\s\[\d]*\d+(?:\.\d+){1,2}(?:[-+]\S*)?|(?i:unreleased))(?:\s+(?:-\s+|\()(.*?)\)?)?\s*$`)
+	sectionHeading = regexp.MustCompile(`^###\s+(.+?)\s*$`)
+	linkReference = regexp.MustCompile(`^\[([^\]]+)\]:\s*(\S+)\s*$`)
+	legacyReleaseHeading = regexp.MustCompile(`^## \[ (\S+) \] - (\d{4})-(\d{1,2})-(\d{1,2}) \d{1,2}:\d{1,2}:\d{1,2}$`)
+)
+
+// Changelog is a Keep a Changelog file. every line of the parsed file belongs to exactly one part, so an unmodified Changelog renders back to the original file
+type Changelog struct {
+	Preamble []string
+	Releases []*Release
+	Links []*Link
+}
+
+// Release is a '## [version] - date' block with the lines before its first section and its sections
+type Release struct {
+	Heading string
+	Version string
+	Date string
+	Intro []string
+	Sections []*Section
+}
+
+// Section is a '### Added' (Changed, Fixed ...) block within a release
+type Section struct {
+	Heading string
+	Name string
+	Lines []string
+}
+
+// Link is a link reference definition, eg. '[1.2.3]: https://github.com/owner/repo/compare/v1.2.2...v1.2.3'
+type Link struct {
+	Label string
+	URL string
+}
+
+func (l *Link) String() string {
+	return "[" + l.Label + "]: " + l.URL
+}
+
+// New returns an empty changelog with the standard header and an Unreleased block
+func New() *Changelog {
+	return &Changelog{
+		Preamble: strings.Split(header, "\n"),
+		Releases: []*Release{{Heading: "## [" + Unreleased + "]", Version: Unreleased, Intro: []string{""}}},
+	}
+}
+
+// newChangelog is New with the configured header
+func newChangelog() (*Changelog, error) {
+	preamble, err := headerLines()
+	if err != nil {
+		return nil, err
+	}
+
+	c := New()
+	c.Preamble = preamble
+	return c, nil
+}
+
+func parseReleaseHeading(line string) (string, string, bool) {
+	if m := releaseHeading.FindStringSubmatch(line); m != nil {
+		return m[1], m[2], true
+	}
+	if m := plainReleaseHeading.FindStringSubmatch(line); m != nil {
+		return m[1], m[2], true
+	}
+	return "", "", false
+}
+
+func Parse(content string) *Changelog {
+	if strings.TrimSpace(content) == "" {
+		return New()
+	}
+
+	lines := strings.Split(content, "\n")
+
+	// link references at the very end of the file belong to the changelog rather than the last release
+	tail := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		if linkReference.MatchString(lines[i]) {
+			tail = i
+		} else if strings.TrimSpace(lines[i]) != "" {
+			break
+		}
+	}
+
+	c := &Changelog{}
+	var release *Release
+	var section *Section
+
+	for _, line := range lines[:tail] {
+		if version, date, ok := parseReleaseHeading(line); ok {
+			release = &Release{Heading: line, Version: version, Date: date}
+			section = nil
+			c.Releases = append(c.Releases, release)
+			continue
+		}
+
+		switch {
+		case release == nil:
+			c.Preamble = append(c.Preamble, line)
+		case sectionHeading.MatchString(line):
+			section = &Section{Heading: line, Name: sectionHeading.FindStringSubmatch(line)[1]}
+			release.Sections = append(release.Sections, section)
+		case section != nil:
+			section.Lines = append(section.Lines, line)
+		default:
+			release.Intro = append(release.Intro, line)
+		}
+	}
+
+	if tail < len(lines) {
+		for _, line := range lines[tail:] {
+			if m := linkReference.FindStringSubmatch(line); m != nil {
+				c.Links = append(c.Links, &Link{Label: m[1], URL: m[2]})
+			} else {
+				// blank lines between link references are not kept, but a trailing newline is
+				c.Links = append(c.Links, nil)
+			}
+		}
+	}
+
+	return c
+}
+
+// Upgrade rewrites the header and the '## [ v1.2.3 ] - 2022-3-4 5:6:7' release headings written by older versions of GOG.
+// it reports if the header was replaced and the versions whose heading was rewritten. releases are otherwise left as they are
+func (c *Changelog) Upgrade() (bool, []string, error) {
+	upgradedHeader := false
+	if strings.TrimSpace(strings.Join(c.Preamble, "\n")) == strings.TrimSpace(legacyHeader) {
+		preamble, err := headerLines()
+		if err != nil {
+			return false, nil, err
+		}
+		c.Preamble = preamble
+		upgradedHeader = true
+	}
+
+	upgraded := []string{}
+	for _, r := range c.Releases {
+		m := legacyReleaseHeading.FindStringSubmatch(r.Heading)
+		if m == nil {
+			continue
+		}
+
+		r.Date = fmt.Sprintf("%s-%02s-%02s", m[2], m[3], m[4])
+		r.Heading = fmt.Sprintf("## [%s] - %s", r.Version, r.Date)
+		upgraded = append(upgraded, r.Version)
+	}
+
+	return upgradedHeader, upgraded, nil
+}
+
+func (c *Changelog) Lines() []string {
+	lines := append([]string{}, c.Preamble...)
+	for _, r := range c.Releases {
+		lines = append(lines, r.Lines()...)
+	}
+	for _, l := range c.Links {
+		if l == nil {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, l.String())
+	}
+	return lines
+}
+
+func (c *Changelog) String() string {
+	return strings.Join(c.Lines(), "\n")
+}
+
+// Unreleased returns the Unreleased block, if the changelog has one
+func (c *Changelog) Unreleased() *Release {
+	for _, r := range c.Releases {
+		if strings.EqualFold(r.Version, Unreleased) {
+			return r
+		}
+	}
+	return nil
+}
+
+// Latest returns the most recent release, ignoring the Unreleased block
+func (c *Changelog) Latest() *Release {
+	for _, r := range c.Releases {
+		if !strings.EqualFold(r.Version, Unreleased) {
+			return r
+		}
+	}
+	return nil
+}
+
+func (c *Changelog) release(offset int, from *Release) *Release {
+	for i, r := range c.Releases {
+		if r == from && i + offset >= 0 && i + offset < len(c.Releases) {
+			return c.Releases[i+offset]
+		}
+	}
+	return nil
+}
+
+func (c *Changelog) Link(label string) (*Link, int) {
+	for i, l := range c.Links {
+		if l != nil && strings.EqualFold(l.Label, label) {
+			return l, i
+		}
+	}
+	return nil, -1
+}
+
+// Add inserts release below the Unreleased block, moving anything recorded as unreleased into it, and extends the compare links
+func (c *Changelog) Add(release *Release) {
+	previous := c.Latest()
+
+	index := 0
+	if unreleased := c.Unreleased(); unreleased != nil {
+		release.merge(unreleased)
+		unreleased.clear()
+
+		for i, r := range c.Releases {
+			if r == unreleased {
+				index = i + 1
+			}
+		}
+	}
+
+	c.Releases = append(c.Releases[:index], append([]*Release{release}, c.Releases[index:]...)...)
+
+	if previous != nil {
+		c.addLinks(release, previous)
+	}
+}
+
+// addLinks extends existing compare links (eg. '.../compare/v1.2.2...v1.2.3') to the new release. without any to follow, no links are added
+func (c *Changelog) addLinks(release, previous *Release) {
+	var link *Link
+	at := -1
+
+	if unreleased, i := c.Link(Unreleased); unreleased != nil {
+		if url, ok := compareURL(unreleased.URL, previous.Version, previous.Version, release.Version); ok {
+			link, at = &Link{Label: release.Version, URL: url}, i + 1
+		}
+		if url, ok := compareURL(unreleased.URL, previous.Version, release.Version, "HEAD"); ok {
+			unreleased.URL = url
+		}
+	}
+
+	if prev, i := c.Link(previous.Version); link == nil && prev != nil {
+		if older := c.release(1, previous); older != nil {
+			if url, ok := compareURL(prev.URL, older.Version, previous.Version, release.Version); ok {
+				link, at = &Link{Label: release.Version, URL: url}, i
+			}
+		}
+	}
+
+	if link == nil {
+		return
+	}
+
+	if existing, _ := c.Link(release.Version); existing != nil {
+		existing.URL = link.URL
+		return
+	}
+	c.Links = append(c.Links[:at], append([]*Link{link}, c.Links[at:]...)...)
+}
+
+// SetLink points the link reference for label at url, adding it next to the links of the neighbouring releases when it does not exist yet
+func (c *Changelog) SetLink(label, url string) {
+	if existing, _ := c.Link(label); existing != nil {
+		existing.URL = url
+		return
+	}
+
+	// blank lines at the end of the links are kept there, and a new block of links is set apart from the last release
+	end := len(c.Links)
+	for end > 0 && c.Links[end-1] == nil {
+		end--
+	}
+	if end == 0 {
+		if lines := c.Lines(); len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			c.Links = append([]*Link{nil}, c.Links...)
+			end++
+		}
+		if len(c.Links) == end {
+			c.Links = append(c.Links, nil)
+		}
+	}
+
+	// links are kept in the order of the releases, so the new link goes before that of the first older release
+	at := end
+	older := false
+	for _, r := range c.Releases {
+		if older {
+			if _, i := c.Link(r.Version); i >= 0 && i < at {
+				at = i
+			}
+		}
+		older = older || strings.EqualFold(r.Version, label)
+	}
+
+	c.Links = append(c.Links[:at], append([]*Link{{Label: label, URL: url}}, c.Links[at:]...)...)
+}
+
+// compareURL turns a compare url from oldFrom into one comparing from...to. a prefix on the refs of the url (eg. 'v' for headings without one) is kept
+func compareURL(url, oldFrom, from, to string) (string, bool) {
+	i := strings.LastIndex(url, "...")
+	if i < 0 || !strings.HasSuffix(url[:i], oldFrom) {
+		return "", false
+	}
+
+	start := strings.LastIndex(url[:i], "/") + 1
+	prefix := strings.TrimSuffix(url[start:i], oldFrom)
+	if to != "HEAD" {
+		to = prefix + to
+	}
+	return url[:start] + prefix + from + "..." + to, true
+}
+
+func (r *Release) Lines() []string {
+	lines := append([]string{r.Heading}, r.Intro...)
+	for _, s := range r.Sections {
+		lines = append(lines, s.Heading)
+		lines = append(lines, s.Lines...)
+	}
+	return lines
+}
+
+func (r *Release) String() string {
+	return strings.Join(r.Lines(), "\n")
+}
+
+func (r *Release) Section(name string) *Section {
+	for _, s := range r.Sections {
+		if strings.EqualFold(s.Name, name) {
+			return s
+		}
+	}
+	return nil
+}
+
+// merge moves the content of other (eg. the Unreleased block) into r, joining sections with the same name
+func (r *Release) merge(other *Release) {
+	if intro := content(other.Intro); len(intro) > 0 {
+		r.Intro = insertBeforeTrailingBlanks(r.Intro, intro)
+	}
+
+	for _, s := range other.Sections {
+		items := content(s.Lines)
+		if len(items) == 0 {
+			continue
+		}
+
+		if existing := r.Section(s.Name); existing != nil {
+			existing.Lines = insertBeforeTrailingBlanks(existing.Lines, items)
+			continue
+		}
+		r.Sections = append(r.Sections, &Section{Heading: "### " + s.Name, Name: s.Name, Lines: append(append([]string{""}, items...), "")})
+	}
+}
+
+func (r *Release) clear() {
+	r.Intro = []string{""}
+	r.Sections = nil
+}
+
+// content drops the blank lines surrounding lines
+func content(lines []string) []string {
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return lines[start:end]
+}
+
+func insertBeforeTrailingBlanks(lines, insert []string) []string {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	out := append([]string{}, lines[:end]...)
+	if end == 0 {
+		out = append(out, "")
+	}
+	out = append(out, insert...)
+	if end == len(lines) {
+		return append(out, "")
+	}
+	return append(out, lines[end:]...)
+}
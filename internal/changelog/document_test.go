@@ -0,0 +1,123 @@
+package changelog
+
+import (
+	"testing"
+)
+
+const keepAChangelog = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## Guidelines
+
+Keep entries short.
+
+## [Unreleased]
+
+### Added
+
+- dark mode
+
+## [v1.3.0] - 2026-10-01
+
+> ABC-2 search
+
+### Fixed
+
+- crash on start
+
+## v1.2.0 - 2026-09-01
+
+- ABC-1 login
+
+[Unreleased]: https://github.com/owner/app/compare/v1.3.0...HEAD
+[v1.3.0]: https://github.com/owner/app/compare/v1.2.0...v1.3.0
+`
+
+const legacyChangelog = `
+# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/)
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+## [ v0.2.0 ] - 2022-3-4 5:6:7
+* ABC-2 the second feature
+
+## [ v0.1.0 ] - 2022-1-2 3:4:5
+* ABC-1 the first feature
+`
+
+func versions(c *Changelog) []string {
+	var v []string
+	for _, r := range c.Releases {
+		v = append(v, r.Version)
+	}
+	return v
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		content string
+		versions []string
+		links int
+	}{
+		{"keep a changelog", keepAChangelog, []string{"Unreleased", "v1.3.0", "v1.2.0"}, 3},
+		{"legacy", legacyChangelog, []string{"v0.2.0", "v0.1.0"}, 0},
+		{"no releases", "# Changelog\n\n## Guidelines\n\n## Notes (internal)\n", nil, 0},
+	}
+
+	for _, tt := range tests {
+		c := Parse(tt.content)
+
+		if got := c.String(); got != tt.content {
+			t.Errorf("%s: Parse(...).String() changed the changelog to\n%s", tt.name, got)
+		}
+
+		if got := versions(c); len(got) != len(tt.versions) {
+			t.Errorf("%s: versions = %v, want %v", tt.name, got, tt.versions)
+		} else {
+			for i := range got {
+				if got[i] != tt.versions[i] {
+					t.Errorf("%s: versions = %v, want %v", tt.name, got, tt.versions)
+					break
+				}
+			}
+		}
+
+		if len(c.Links) != tt.links {
+			t.Errorf("%s: %d links, want %d", tt.name, len(c.Links), tt.links)
+		}
+	}
+}
+
+func TestParseReleaseHeading(t *testing.T) {
+	tests := []struct {
+		line string
+		version string
+		date string
+		release bool
+	}{
+		{"## [1.2.3] - 2026-01-02", "1.2.3", "2026-01-02", true},
+		{"## [Unreleased]", "Unreleased", "", true},
+		{"## [ v0.1.0 ] - 2022-1-2 3:4:5", "v0.1.0", "2022-1-2 3:4:5", true},
+		{"## v1.2.3 - 2026-01-02", "v1.2.3", "2026-01-02", true},
+		{"## 1.2.3 (2026-01-02)", "1.2.3", "2026-01-02", true},
+		{"## api-v2.0.0-rc.1", "api-v2.0.0-rc.1", "", true},
+		{"## 1.2", "1.2", "", true},
+		{"## Unreleased", "Unreleased", "", true},
+		{"## Guidelines", "", "", false},
+		{"## Notes (internal)", "", "", false},
+		{"## 2026 roadmap", "", "", false},
+		{"### 1.2.3", "", "", false},
+	}
+
+	for _, tt := range tests {
+		version, date, ok := parseReleaseHeading(tt.line)
+		if ok != tt.release || version != tt.version || date != tt.date {
+			t.Errorf("parseReleaseHeading(%q) = %q, %q, %t, want %q, %q, %t", tt.line, version, date, ok, tt.version, tt.date, tt.release)
+		}
+	}
+}
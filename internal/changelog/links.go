@@ -0,0 +1,87 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/models"
+)
+
+var wordCharacter = regexp.MustCompile(`^\w$`)
+
+// ticketURL is the issue tracker page of ticket, when a ticket link is configured
+func ticketURL(ticket string) string {
+	template := config.AppConfig().ChangelogLinks().Ticket
+	if template == "" || ticket == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, config.TicketPlaceholder, ticket)
+}
+
+// linkTicket links each mention of ticket in text to the issue tracker, when a ticket link is configured
+func linkTicket(text, ticket string) string {
+	url := ticketURL(ticket)
+	if url == "" {
+		return text
+	}
+
+	// word boundaries stop 'ABC-1' from matching within 'ABC-12', but only apply next to word characters
+	pattern := regexp.QuoteMeta(ticket)
+	if wordCharacter.MatchString(ticket[:1]) {
+		pattern = `\b` + pattern
+	}
+	if wordCharacter.MatchString(ticket[len(ticket)-1:]) {
+		pattern += `\b`
+	}
+
+	return regexp.MustCompile(pattern).ReplaceAllLiteralString(text, fmt.Sprintf("[%s](%s)", ticket, url))
+}
+
+// commitURL is the forge page of the change, when a commit link is configured
+func commitURL(c models.Change) string {
+	template := config.AppConfig().ChangelogLinks().Commit
+	if template == "" || c.ShortHash == "" {
+		return ""
+	}
+
+	hash := c.Hash
+	if hash == "" {
+		hash = c.ShortHash
+	}
+
+	return strings.NewReplacer(config.HashPlaceholder, hash, config.ShortHashPlaceholder, c.ShortHash).Replace(template)
+}
+
+// commitReference is the short hash of the change, linked to the commit on the forge when a commit link is configured
+func commitReference(c models.Change) string {
+	if c.ShortHash == "" {
+		return ""
+	}
+
+	reference := fmt.Sprintf("`%s`", c.ShortHash)
+	if url := commitURL(c); url != "" {
+		return fmt.Sprintf("[%s](%s)", reference, url)
+	}
+	return reference
+}
+
+// compareLink is the forge page comparing from with to, when a compare link is configured
+func compareLink(from, to string) (string, bool) {
+	template := config.AppConfig().ChangelogLinks().Compare
+	if template == "" {
+		return "", false
+	}
+	return strings.NewReplacer(config.FromPlaceholder, from, config.ToPlaceholder, to).Replace(template), true
+}
+
+// linkCompare links the release tagged tag to its changes since from, and the Unreleased block to the changes since tag, when a compare link is configured
+func (c *Changelog) linkCompare(tag, from string) {
+	if url, ok := compareLink(from, tag); ok && from != "" {
+		c.SetLink(tag, url)
+	}
+	if url, ok := compareLink(tag, "HEAD"); ok && c.Unreleased() != nil {
+		c.SetLink(c.Unreleased().Version, url)
+	}
+}
@@ -0,0 +1,144 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/models"
+	"sykesdev.ca/gog/internal/output"
+)
+
+// tagMessagePrefix is the '(v1.2.3): ' GOG puts before the feature in release tag messages
+var tagMessagePrefix = regexp.MustCompile(`^\([^)]*\):\s*`)
+
+// Rebuild generates the releases of existing from the version tags of the repository, keeping its header and Unreleased block.
+// with since, the releases from that tag back are kept as they are and only the newer ones are generated. the generated tags are returned newest first
+func Rebuild(r *git.Repository, existing *Changelog, since string) (*Changelog, []string, error) {
+	tags, err := r.VersionTags()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the release tags. %v", err)
+	}
+
+	c := &Changelog{Preamble: existing.Preamble}
+	if len(content(c.Preamble)) == 0 {
+		if c.Preamble, err = headerLines(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	unreleased := existing.Unreleased()
+	if unreleased == nil {
+		unreleased = New().Unreleased()
+	}
+
+	first := 0
+	var kept []*Release
+	if since != "" {
+		first = -1
+		for i, t := range tags {
+			if t.Name == since {
+				first = i + 1
+			}
+		}
+		if first < 0 {
+			return nil, nil, output.Errorf(output.CodeUsage, "'%s' is not a release tag of this repository", since)
+		}
+
+		// the kept release may have been written without the tag prefix, eg. '## [1.2.0]' for v1.2.0
+		for i, release := range existing.Releases {
+			if strings.EqualFold(release.Version, since) || release.Version == tags[first-1].Version.NoPrefix() {
+				kept = existing.Releases[i:]
+				break
+			}
+		}
+		if kept == nil {
+			return nil, nil, output.Errorf(output.CodeUsage, "there is no release for %s in %s to keep", since, File())
+		}
+	}
+	c.Releases = append([]*Release{unreleased}, kept...)
+
+	// links of the kept releases stay, and those of the generated ones are kept unless a compare link replaces them
+	for _, l := range existing.Links {
+		if l != nil {
+			c.Links = append(c.Links, l)
+		}
+	}
+	if len(c.Links) > 0 {
+		c.Links = append(c.Links, nil)
+	}
+
+	var rebuilt []string
+	for i := first; i < len(tags); i++ {
+		previous := git.VersionTag{}
+		if i > 0 {
+			previous = tags[i-1]
+		}
+
+		entry, err := tagEntry(r, previous, tags[i])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		release, err := entry.Build()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		logging.Instance().Debugf("rebuilt changelog entry for %s with %d change(s)", tags[i].Name, len(entry.Changes))
+
+		// each release is newer than those before it, so it goes straight below the Unreleased block
+		c.Releases = append([]*Release{unreleased, release}, c.Releases[1:]...)
+		if release.Version == tags[i].Name {
+			c.linkCompare(tags[i].Name, previous.Name)
+		}
+		rebuilt = append([]string{tags[i].Name}, rebuilt...)
+	}
+
+	return c, rebuilt, nil
+}
+
+// tagEntry is the changelog entry for the commits since the previous tag, with the feature read from the tag message
+func tagEntry(r *git.Repository, previous, tag git.VersionTag) (*ChangelogEntry, error) {
+	details, err := r.TagDetails(tag.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag %s. %v", tag.Name, err)
+	}
+
+	commits, err := r.CommitRange(previous.Name, tag.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the commits of %s. %v", tag.Name, err)
+	}
+
+	// tags made by GOG hold '(v1.2.3): TICKET comment', while the message of any other tag is used as the comment
+	summary := strings.SplitN(details.Message, "\n", 2)[0]
+	feature := &models.Feature{Comment: summary}
+	if tagMessagePrefix.MatchString(summary) {
+		fields := strings.SplitN(tagMessagePrefix.ReplaceAllString(summary, ""), " ", 2)
+		feature = &models.Feature{Ticket: fields[0]}
+		if len(fields) == 2 {
+			feature.Comment = fields[1]
+		}
+	}
+
+	changes := []models.Change{}
+	for _, commit := range commits {
+		// the release commits made by 'gog release' only hold the changelog and version files
+		if strings.HasPrefix(commit.Subject, "release " + tag.Name + ":") {
+			continue
+		}
+		changes = append(changes, models.NewChange(commit, feature.Ticket))
+	}
+
+	added := previous.Name == "" || tag.Version.Core[0] != previous.Version.Core[0] || tag.Version.Core[1] != previous.Version.Core[1]
+
+	entry := NewChangelogEntry(feature, r, tag.Version, added)
+	entry.Tag = tag.Name
+	entry.Date = details.Date
+	entry.Changes = changes
+	entry.Previous = previous.Version
+
+	return entry, nil
+}
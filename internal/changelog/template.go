@@ -0,0 +1,157 @@
+package changelog
+
+import (
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
+)
+
+// DefaultHeaderTemplate is written at the top of a new changelog unless changelog.templates.header is configured
+const DefaultHeaderTemplate = header
+
+// DefaultEntryTemplate renders a release unless changelog.templates.entry is configured, eg.
+//
+//	## [v1.2.0] - 2022-03-04
+//
+//	> ABC-12 the feature
+//
+//	### Added
+//
+//	- `abc1234` - ABC-12 feat: the thing
+const DefaultEntryTemplate = `## [{{ .Version }}] - {{ .Date }}
+{{ if .Summary }}
+> {{ .Summary }}
+{{ end }}
+{{- range .Sections }}
+### {{ .Name }}
+
+{{ range .Changes }}- {{ if .Reference }}{{ .Reference }} - {{ end }}{{ .Title }}
+{{ end }}
+{{- end }}`
+
+// HeaderData is the data the header template is rendered with
+type HeaderData struct {
+	// Project is the name of the project directory
+	Project string
+	// Component is the monorepo component the changelog belongs to, if any
+	Component string
+}
+
+// EntryData is the data the entry template is rendered with
+type EntryData struct {
+	// Version is the release tag, eg. 'v1.2.0'
+	Version string
+	// Previous is the tag of the release this one follows, if known
+	Previous string
+	// Date is the release date as YYYY-MM-DD, and Time the full release time in UTC
+	Date string
+	Time time.Time
+	Ticket string
+	Comment string
+	// Summary is the ticket and comment of the feature, with the ticket linked when a ticket link is configured
+	Summary string
+	// TicketURL and CompareURL are the configured ticket and compare links, or empty
+	TicketURL string
+	CompareURL string
+	// Sections are the non-empty Keep a Changelog sections in order, while Changes lists every change of the release
+	Sections []SectionData
+	Changes []ChangeData
+}
+
+// SectionData is a Keep a Changelog section of the release, eg. 'Added'
+type SectionData struct {
+	Name string
+	Changes []ChangeData
+}
+
+// ChangeData is a commit of the release
+type ChangeData struct {
+	Category string
+	Hash string
+	ShortHash string
+	Author string
+	// Subject is the commit subject, and Title the subject with the ticket linked when a ticket link is configured
+	Subject string
+	Title string
+	// URL is the configured commit link or empty, and Reference the short hash in backticks, linked to URL when set
+	URL string
+	Reference string
+}
+
+func parseTemplate(name, configured, builtin string) (*template.Template, error) {
+	text := builtin
+	if configured != "" {
+		logging.Instance().Debugf("using configured changelog %s template", name)
+		text = configured
+	}
+
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, output.Errorf(output.CodeConfig, "invalid changelog.templates.%s. %v", name, err)
+	}
+	return t, nil
+}
+
+func execute(t *template.Template, data interface{}) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", output.Errorf(output.CodeConfig, "failed to render changelog.templates.%s. %v", t.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// headerLines renders the header of a new changelog
+func headerLines() ([]string, error) {
+	t, err := parseTemplate("header", config.AppConfig().ChangelogTemplates().Header, DefaultHeaderTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	data := HeaderData{}
+	if projectRoot, err := common.GitProjectRoot(); err == nil {
+		data.Project = filepath.Base(projectRoot)
+	}
+	if component, ok := config.AppConfig().ActiveComponent(); ok {
+		data.Component = component.Name
+	}
+
+	text, err := execute(t, data)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// renderRelease renders data with the entry template and parses the result back into a release, so that it can be merged with the Unreleased block like any other
+func renderRelease(data EntryData) (*Release, error) {
+	t, err := parseTemplate("entry", config.AppConfig().ChangelogTemplates().Entry, DefaultEntryTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := execute(t, data)
+	if err != nil {
+		return nil, err
+	}
+
+	c := Parse(text)
+	if len(c.Releases) != 1 || len(content(c.Preamble)) > 0 || len(c.Links) > 0 {
+		return nil, output.Errorf(output.CodeConfig, "changelog.templates.entry must render exactly one release, starting with its '## ' heading. got:\n%s", text)
+	}
+
+	// compare links and 'changelog rebuild -since' find releases by the version in their heading, so a heading without it still works but is not linked
+	release := c.Releases[0]
+	if release.Version != data.Version {
+		logging.Instance().Warnf("the changelog heading '%s' does not hold the version %s. compare links will not be added for it", release.Heading, data.Version)
+	}
+
+	logging.Instance().Debugf("rendered changelog entry for %s with %d section(s)", data.Version, len(release.Sections))
+
+	return release, nil
+}
@@ -1,4 +1,4 @@
 package constants
 
-var FullSemverRegexp string = `^([a-zA-Z])*(-)?([0-9])+\.([0-9])+\.([0-9])+$`
+var FullSemverRegexp string = `^([a-zA-Z])*(-)?([0-9])+\.([0-9])+\.([0-9])+(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`
 var VersionPrefixRegexp string = `^([a-zA-Z])*(-)?`
\ No newline at end of file
@@ -3,6 +3,8 @@ package common
 import (
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 )
 
 func GitProjectRoot() (string, error) {
@@ -39,4 +41,17 @@ func PathExists(path string) bool {
 		return false
 	}
 	return true
-}
\ No newline at end of file
+}
+
+// ExpandHome replaces a leading '~/' in path with the home directory of the user
+func ExpandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
@@ -0,0 +1,121 @@
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+const (
+	Major = "MAJOR"
+	Minor = "MINOR"
+	Patch = "PATCH"
+)
+
+var headerRegexp = regexp.MustCompile(`^([a-zA-Z]+)(\(([^()]*)\))?(!)?: (.+)$`)
+var breakingRegexp = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+var changelogRegexp = regexp.MustCompile(`(?mi)^Changelog:[ \t]*(.+?)[ \t]*$`)
+
+type Commit struct {
+	Type string `json:"type"`
+	Scope string `json:"scope,omitempty"`
+	Breaking bool `json:"breaking"`
+	Description string `json:"description"`
+	Conventional bool `json:"conventional"`
+	// Changelog is the value of a 'Changelog: fixed' trailer, which overrides the type when categorizing the commit
+	Changelog string `json:"changelog,omitempty"`
+
+	Source git.Commit `json:"source"`
+}
+
+// Parse reads a conventional commit from the subject and body of c, ignoring a leading ticket key such as 'ABC-12'
+func Parse(c git.Commit, ticket string) Commit {
+	subject := strings.TrimSpace(c.Subject)
+	if ticket != "" && strings.HasPrefix(subject, ticket) {
+		subject = strings.TrimSpace(strings.TrimPrefix(subject, ticket))
+	}
+
+	parsed := Commit{Description: subject, Source: c}
+
+	if match := headerRegexp.FindStringSubmatch(subject); match != nil {
+		parsed.Type = strings.ToLower(match[1])
+		parsed.Scope = match[3]
+		parsed.Breaking = match[4] == "!"
+		parsed.Description = match[5]
+		parsed.Conventional = true
+	}
+
+	if breakingRegexp.MatchString(c.Body) {
+		parsed.Breaking = true
+	}
+
+	if match := changelogRegexp.FindAllStringSubmatch(c.Body, -1); match != nil {
+		parsed.Changelog = strings.ToLower(match[len(match)-1][1])
+	}
+
+	return parsed
+}
+
+func ParseAll(commits []git.Commit, ticket string) []Commit {
+	parsed := make([]Commit, 0, len(commits))
+	for _, c := range commits {
+		parsed = append(parsed, Parse(c, ticket))
+	}
+	return parsed
+}
+
+func (c Commit) Bump() string {
+	switch {
+	case c.Breaking:
+		return Major
+	case c.Type == "feat":
+		return Minor
+	default:
+		return Patch
+	}
+}
+
+func rank(level string) int {
+	switch level {
+	case Major:
+		return 3
+	case Minor:
+		return 2
+	case Patch:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// InferBump returns the highest release level implied by commits along with a human readable reason for each commit
+func InferBump(commits []Commit) (string, []string) {
+	level := Patch
+	var reasons []string
+
+	for _, c := range commits {
+		bump := c.Bump()
+
+		var reason string
+		switch {
+		case c.Breaking:
+			reason = "breaking change"
+		case c.Conventional:
+			reason = fmt.Sprintf("'%s' commit", c.Type)
+		default:
+			reason = "non-conventional commit"
+		}
+		reasons = append(reasons, fmt.Sprintf("`%s` %s (%s => %s)", c.Source.ShortHash, c.Description, reason, bump))
+
+		if rank(bump) > rank(level) {
+			level = bump
+		}
+	}
+
+	logging.Instance().Debugf("inferred %s release from %d commits", level, len(commits))
+
+	return level, reasons
+}
@@ -0,0 +1,60 @@
+package conventional
+
+import (
+	"testing"
+
+	"sykesdev.ca/gog/internal/git"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		subject string
+		body string
+		want Commit
+	}{
+		{"feat: login form", "", Commit{Type: "feat", Description: "login form", Conventional: true}},
+		{"ABC-1 fix(api): null check", "", Commit{Type: "fix", Scope: "api", Description: "null check", Conventional: true}},
+		{"Feat!: drop v1 endpoints", "", Commit{Type: "feat", Breaking: true, Description: "drop v1 endpoints", Conventional: true}},
+		{"refactor(db)!: new schema", "", Commit{Type: "refactor", Scope: "db", Breaking: true, Description: "new schema", Conventional: true}},
+		{"fix: rename flag", "details\n\nBREAKING CHANGE: -x is now -y", Commit{Type: "fix", Breaking: true, Description: "rename flag", Conventional: true}},
+		{"fix: rename flag", "BREAKING-CHANGE: -x is now -y", Commit{Type: "fix", Breaking: true, Description: "rename flag", Conventional: true}},
+		{"fix: mention", "not a BREAKING CHANGE: footer", Commit{Type: "fix", Description: "mention", Conventional: true}},
+		{"chore: tidy", "Changelog: Removed", Commit{Type: "chore", Description: "tidy", Conventional: true, Changelog: "removed"}},
+		{"update readme", "", Commit{Description: "update readme"}},
+		{"feat:missing space", "", Commit{Description: "feat:missing space"}},
+	}
+
+	for _, tt := range tests {
+		got := Parse(git.Commit{Subject: tt.subject, Body: tt.body}, "ABC-1")
+		got.Source = git.Commit{}
+		if got != tt.want {
+			t.Errorf("Parse(%q, %q) = %+v, want %+v", tt.subject, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestInferBump(t *testing.T) {
+	tests := []struct {
+		name string
+		commits []git.Commit
+		want string
+	}{
+		{"no commits", nil, Patch},
+		{"fixes", []git.Commit{{Subject: "fix: a"}, {Subject: "docs: b"}}, Patch},
+		{"non-conventional", []git.Commit{{Subject: "did some work"}}, Patch},
+		{"feature", []git.Commit{{Subject: "fix: a"}, {Subject: "feat: b"}}, Minor},
+		{"breaking bang", []git.Commit{{Subject: "feat: a"}, {Subject: "fix!: b"}}, Major},
+		{"breaking footer", []git.Commit{{Subject: "fix: a", Body: "BREAKING CHANGE: b"}, {Subject: "feat: c"}}, Major},
+		{"ticket prefix", []git.Commit{{Subject: "ABC-1 feat(ui)!: c"}}, Major},
+	}
+
+	for _, tt := range tests {
+		level, reasons := InferBump(ParseAll(tt.commits, "ABC-1"))
+		if level != tt.want {
+			t.Errorf("%s: InferBump = %s, want %s", tt.name, level, tt.want)
+		}
+		if len(reasons) != len(tt.commits) {
+			t.Errorf("%s: %d reasons for %d commits", tt.name, len(reasons), len(tt.commits))
+		}
+	}
+}
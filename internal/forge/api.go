@@ -0,0 +1,119 @@
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sykesdev.ca/gog/internal/logging"
+)
+
+// apiClient is a minimal JSON client for forges without a dedicated client library (GitLab, Gitea)
+type apiClient struct {
+	baseURL string
+	client *http.Client
+}
+
+func newAPIClient(baseURL string, client *http.Client) *apiClient {
+	return &apiClient{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+type apiError struct {
+	Method string
+	URL string
+	StatusCode int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s returned %d. %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*apiError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+func (a *apiClient) send(req *http.Request, out interface{}) error {
+	logging.Instance().Debugf("forge request: %s %s", req.Method, req.URL)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &apiError{Method: req.Method, URL: req.URL.String(), StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(respBytes))}
+	}
+
+	if out == nil || len(respBytes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBytes, out)
+}
+
+func (a *apiClient) do(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequest(method, a.baseURL + path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return a.send(req, out)
+}
+
+// upload sends a file as a multipart form field
+func (a *apiClient) upload(path, field, file string, out interface{}) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(buf)
+
+	part, err := form.CreateFormFile(field, filepath.Base(file))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, a.baseURL + path, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	return a.send(req, out)
+}
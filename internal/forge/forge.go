@@ -0,0 +1,234 @@
+package forge
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderGitea = "gitea"
+)
+
+var Providers = []string{ProviderGitHub, ProviderGitLab, ProviderGitea}
+
+type PullRequest struct {
+	Number int `json:"number"`
+	URL string `json:"url"`
+	Title string `json:"title"`
+	Body string `json:"body"`
+	Head string `json:"head"`
+	Base string `json:"base"`
+}
+
+type Asset struct {
+	Name string `json:"name"`
+	URL string `json:"url"`
+}
+
+type Release struct {
+	ID int64 `json:"id,omitempty"`
+	Tag string `json:"tag"`
+	Name string `json:"name"`
+	Body string `json:"body"`
+	URL string `json:"url,omitempty"`
+	PreRelease bool `json:"pre_release"`
+	Assets []Asset `json:"assets,omitempty"`
+}
+
+// Forge is a hosted git service (GitHub, GitLab or Gitea) which GOG can open pull requests and publish releases on
+type Forge interface {
+	Name() string
+	CreatePullRequest(pr PullRequest) (*PullRequest, error)
+	CreateRelease(release Release) (*Release, error)
+	// GetRelease returns nil without an error when there is no release for the tag
+	GetRelease(tag string) (*Release, error)
+	UpdateRelease(release Release) (*Release, error)
+	UploadAsset(release *Release, path string) (*Asset, error)
+	ListReleases() ([]Release, error)
+}
+
+type Options struct {
+	Provider string
+	// BaseURL is the web root of a self-hosted instance (eg. https://git.example.com). empty uses the public instance of the provider
+	BaseURL string
+	Token string
+	Owner string
+	Name string
+}
+
+// Remote is the host and repository parsed from a git remote url
+type Remote struct {
+	Host string
+	Owner string
+	Name string
+}
+
+func (r Remote) String() string {
+	return fmt.Sprintf("%s/%s/%s", r.Host, r.Owner, r.Name)
+}
+
+var scpRemoteRegexp = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// ParseRemote reads the host, owner and name from https, ssh and scp-like (git@host:owner/name.git) remote urls
+func ParseRemote(remoteURL string) (Remote, error) {
+	var host, path string
+
+	if u, err := url.Parse(remoteURL); err == nil && u.Scheme != "" && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if match := scpRemoteRegexp.FindStringSubmatch(remoteURL); match != nil {
+		host, path = match[1], match[2]
+	} else {
+		return Remote{}, fmt.Errorf("cannot determine forge repository from remote url '%s'. set forge.repository in config", remoteURL)
+	}
+
+	owner, name, err := splitRepository(strings.TrimSuffix(strings.Trim(path, "/"), ".git"))
+	if err != nil {
+		return Remote{}, fmt.Errorf("cannot determine forge repository from remote url '%s'. set forge.repository in config", remoteURL)
+	}
+
+	return Remote{Host: host, Owner: owner, Name: name}, nil
+}
+
+// splitRepository splits owner/name, where the owner may contain nested groups (eg. GitLab subgroups)
+func splitRepository(repository string) (string, string, error) {
+	i := strings.LastIndex(repository, "/")
+	if i <= 0 || i == len(repository) - 1 {
+		return "", "", fmt.Errorf("invalid repository '%s'. expected the form owner/name", repository)
+	}
+	return repository[:i], repository[i + 1:], nil
+}
+
+// DetectProvider guesses the provider from the host name of a remote, defaulting to GitHub
+func DetectProvider(host string) string {
+	host = strings.ToLower(host)
+
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return ProviderGitLab
+	case strings.Contains(host, "gitea"), strings.Contains(host, "codeberg"), strings.Contains(host, "forgejo"):
+		return ProviderGitea
+	default:
+		return ProviderGitHub
+	}
+}
+
+func publicHost(provider string) string {
+	switch provider {
+	case ProviderGitLab:
+		return "gitlab.com"
+	case ProviderGitea:
+		return "gitea.com"
+	default:
+		return "github.com"
+	}
+}
+
+func NewProvider(o Options) (Forge, error) {
+	if o.Owner == "" || o.Name == "" {
+		return nil, fmt.Errorf("cannot create %s forge without a repository. set forge.repository in config", o.Provider)
+	}
+
+	logging.Instance().Debugf("using %s forge for %s/%s (base url: '%s')", o.Provider, o.Owner, o.Name, o.BaseURL)
+
+	switch o.Provider {
+	case ProviderGitHub:
+		return NewGitHub(o.BaseURL, o.Token, o.Owner, o.Name)
+	case ProviderGitLab:
+		return NewGitLab(o.BaseURL, o.Token, o.Owner, o.Name), nil
+	case ProviderGitea:
+		return NewGitea(o.BaseURL, o.Token, o.Owner, o.Name), nil
+	default:
+		return nil, fmt.Errorf("unknown forge provider '%s'. must be one of %s", o.Provider, strings.Join(Providers, ", "))
+	}
+}
+
+// New creates the forge for a repository from its origin url, applying forge settings from the GOG config
+func New(remoteURL string) (Forge, error) {
+	remote, err := ParseRemote(remoteURL)
+
+	if repository := config.AppConfig().ForgeRepository(); repository != "" {
+		if remote.Owner, remote.Name, err = splitRepository(repository); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := config.AppConfig().ForgeProvider()
+	if provider == "" {
+		provider = DetectProvider(remote.Host)
+	}
+
+	baseURL := config.AppConfig().ForgeBaseURL()
+	if baseURL == "" && remote.Host != "" && remote.Host != publicHost(provider) {
+		baseURL = "https://" + remote.Host
+	}
+
+	return NewProvider(Options{
+		Provider: provider,
+		BaseURL: baseURL,
+		Token: config.AppConfig().ForgeToken(),
+		Owner: remote.Owner,
+		Name: remote.Name,
+	})
+}
+
+// PublishRelease creates the release for its tag, or updates the name and notes of the existing one so reruns are safe.
+// assets already attached to the release under the same name are kept rather than uploaded again
+func PublishRelease(f Forge, release Release, assets []string) (*Release, error) {
+	existing, err := f.GetRelease(release.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	var published *Release
+	if existing != nil {
+		logging.Instance().Debugf("updating existing %s release %s", f.Name(), release.Tag)
+
+		release.ID = existing.ID
+		if published, err = f.UpdateRelease(release); err != nil {
+			return nil, err
+		}
+		published.Assets = existing.Assets
+	} else {
+		if published, err = f.CreateRelease(release); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range assets {
+		name := filepath.Base(path)
+		if hasAsset(published, name) {
+			logging.Instance().Infof("release %s already has asset %s ... skipping upload", release.Tag, name)
+			continue
+		}
+
+		asset, err := f.UploadAsset(published, path)
+		if err != nil {
+			return nil, err
+		}
+
+		logging.Instance().Debugf("uploaded %s to release %s", asset.Name, release.Tag)
+		published.Assets = append(published.Assets, *asset)
+	}
+
+	return published, nil
+}
+
+func hasAsset(release *Release, name string) bool {
+	for _, a := range release.Assets {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package forge
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeForge is a local HTTP forge which answers the routes it is given ("METHOD /path") and records every request it receives
+type fakeForge struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	routes map[string]http.HandlerFunc
+	requests []string
+	bodies map[string]map[string]interface{}
+	headers map[string]http.Header
+}
+
+func newFakeForge(t *testing.T, routes map[string]http.HandlerFunc) *fakeForge {
+	f := &fakeForge{routes: routes, bodies: make(map[string]map[string]interface{}), headers: make(map[string]http.Header)}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + r.URL.EscapedPath()
+
+		f.mu.Lock()
+		f.requests = append(f.requests, route)
+		f.headers[route] = r.Header.Clone()
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err == nil {
+				f.bodies[route] = body
+			}
+		}
+		handler, ok := f.routes[route]
+		f.mu.Unlock()
+
+		if !ok {
+			t.Logf("fake forge has no route for %s", route)
+			respond(http.StatusNotFound, map[string]string{"message": "Not Found"})(w, r)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+// called reports if the forge received a request for route
+func (f *fakeForge) called(route string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, r := range f.requests {
+		if r == route {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeForge) body(route string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.bodies[route]
+}
+
+func (f *fakeForge) header(route, name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if h, ok := f.headers[route]; ok {
+		return h.Get(name)
+	}
+	return ""
+}
+
+func respond(status int, body interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+func writeAsset(t *testing.T, name string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("asset"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		url string
+		want Remote
+	}{
+		{"https://github.com/SystemFiles/GOG.git", Remote{Host: "github.com", Owner: "SystemFiles", Name: "GOG"}},
+		{"git@gitlab.com:group/sub/app.git", Remote{Host: "gitlab.com", Owner: "group/sub", Name: "app"}},
+		{"ssh://git@git.example.com:2222/team/app", Remote{Host: "git.example.com", Owner: "team", Name: "app"}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRemote(tt.url)
+		if err != nil {
+			t.Errorf("ParseRemote(%q) failed. %v", tt.url, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRemote(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+
+	if _, err := ParseRemote("not a remote"); err == nil {
+		t.Error("ParseRemote of an invalid url should fail")
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	tests := map[string]string{
+		"github.com": ProviderGitHub,
+		"gitlab.example.com": ProviderGitLab,
+		"codeberg.org": ProviderGitea,
+		"git.example.com": ProviderGitHub,
+	}
+
+	for host, want := range tests {
+		if got := DetectProvider(host); got != want {
+			t.Errorf("DetectProvider(%q) = %s, want %s", host, got, want)
+		}
+	}
+}
+
+func TestPublishReleaseCreates(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/team/app/releases/tags/v1.2.0": respond(http.StatusNotFound, map[string]string{"message": "Not Found"}),
+		"POST /api/v1/repos/team/app/releases": respond(http.StatusCreated, giteaRelease{ID: 7, TagName: "v1.2.0", Name: "v1.2.0", Body: "notes"}),
+		"POST /api/v1/repos/team/app/releases/7/assets": respond(http.StatusCreated, giteaAsset{Name: "app.tar.gz", BrowserDownloadURL: "https://git.example.com/app.tar.gz"}),
+	})
+
+	published, err := PublishRelease(NewGitea(f.URL, "", "team", "app"), Release{Tag: "v1.2.0", Name: "v1.2.0", Body: "notes"}, []string{writeAsset(t, "app.tar.gz")})
+	if err != nil {
+		t.Fatalf("PublishRelease failed. %v", err)
+	}
+
+	if published.ID != 7 || len(published.Assets) != 1 || published.Assets[0].Name != "app.tar.gz" {
+		t.Errorf("unexpected published release %+v", published)
+	}
+	if !f.called("POST /api/v1/repos/team/app/releases") {
+		t.Error("PublishRelease should create a release when there is none for the tag")
+	}
+}
+
+// a rerun (eg. finish -continue after a failed upload) updates the existing release and only uploads the missing assets
+func TestPublishReleaseRerun(t *testing.T) {
+	existing := giteaRelease{ID: 7, TagName: "v1.2.0", Name: "v1.2.0", Body: "old", Assets: []giteaAsset{{Name: "app.tar.gz"}}}
+
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/team/app/releases/tags/v1.2.0": respond(http.StatusOK, existing),
+		"PATCH /api/v1/repos/team/app/releases/7": respond(http.StatusOK, giteaRelease{ID: 7, TagName: "v1.2.0", Name: "v1.2.0", Body: "notes"}),
+		"POST /api/v1/repos/team/app/releases/7/assets": respond(http.StatusCreated, giteaAsset{Name: "checksums.txt"}),
+	})
+
+	assets := []string{writeAsset(t, "app.tar.gz"), writeAsset(t, "checksums.txt")}
+	published, err := PublishRelease(NewGitea(f.URL, "", "team", "app"), Release{Tag: "v1.2.0", Name: "v1.2.0", Body: "notes"}, assets)
+	if err != nil {
+		t.Fatalf("PublishRelease failed. %v", err)
+	}
+
+	if f.called("POST /api/v1/repos/team/app/releases") {
+		t.Error("PublishRelease should not create a second release for the tag")
+	}
+	if body := f.body("PATCH /api/v1/repos/team/app/releases/7"); body["body"] != "notes" {
+		t.Errorf("PublishRelease should update the notes of the existing release, sent %v", body)
+	}
+	if len(published.Assets) != 2 {
+		t.Errorf("expected the existing and the uploaded asset, got %+v", published.Assets)
+	}
+}
+
+func TestPublishReleaseError(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/team/app/releases/tags/v1.2.0": respond(http.StatusNotFound, map[string]string{"message": "Not Found"}),
+		"POST /api/v1/repos/team/app/releases": respond(http.StatusConflict, map[string]string{"message": "release is already present"}),
+	})
+
+	_, err := PublishRelease(NewGitea(f.URL, "", "team", "app"), Release{Tag: "v1.2.0"}, []string{writeAsset(t, "app.tar.gz")})
+	if err == nil {
+		t.Fatal("PublishRelease should fail when the forge rejects the release")
+	}
+	if !strings.Contains(err.Error(), "409") || !strings.Contains(err.Error(), "release is already present") {
+		t.Errorf("error should hold the status and message of the forge. got: %v", err)
+	}
+	if f.called("POST /api/v1/repos/team/app/releases/0/assets") {
+		t.Error("assets should not be uploaded to a release which failed to be created")
+	}
+}
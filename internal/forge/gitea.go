@@ -0,0 +1,178 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"sykesdev.ca/gog/internal/logging"
+)
+
+type Gitea struct {
+	api *apiClient
+	owner string
+	repo string
+}
+
+type giteaPullRequest struct {
+	Number int `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Title string `json:"title"`
+	Body string `json:"body"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+type giteaRelease struct {
+	ID int64 `json:"id"`
+	TagName string `json:"tag_name"`
+	Name string `json:"name"`
+	Body string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	PreRelease bool `json:"prerelease"`
+	Assets []giteaAsset `json:"assets"`
+}
+
+type giteaAsset struct {
+	Name string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// NewGitea creates a Gitea (or Forgejo) forge. a base url selects a self-hosted instance instead of gitea.com
+func NewGitea(baseURL, token, owner, repo string) *Gitea {
+	if baseURL == "" {
+		baseURL = "https://gitea.com"
+	}
+
+	return &Gitea{
+		api: newAPIClient(strings.TrimRight(baseURL, "/") + "/api/v1", httpClient(token, "Authorization", "token ")),
+		owner: owner,
+		repo: repo,
+	}
+}
+
+func (g *Gitea) Name() string {
+	return "gitea"
+}
+
+func (g *Gitea) repoPath(format string, args ...interface{}) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(g.owner), url.PathEscape(g.repo)) + fmt.Sprintf(format, args...)
+}
+
+func (g *Gitea) pullRequest(pr giteaPullRequest) *PullRequest {
+	return &PullRequest{
+		Number: pr.Number,
+		URL: pr.HTMLURL,
+		Title: pr.Title,
+		Body: pr.Body,
+		Head: pr.Head.Ref,
+		Base: pr.Base.Ref,
+	}
+}
+
+// CreatePullRequest opens a pull request, or updates the title and body of an open one for the same branches
+func (g *Gitea) CreatePullRequest(pr PullRequest) (*PullRequest, error) {
+	var open []giteaPullRequest
+	if err := g.api.do(http.MethodGet, g.repoPath("/pulls?state=open&limit=50"), nil, &open); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests. %v", err)
+	}
+
+	body := map[string]string{"title": pr.Title, "body": pr.Body}
+
+	var result giteaPullRequest
+	for _, existing := range open {
+		if existing.Head.Ref == pr.Head && existing.Base.Ref == pr.Base {
+			logging.Instance().Debugf("updating existing pull request #%d for %s", existing.Number, pr.Head)
+
+			if err := g.api.do(http.MethodPatch, g.repoPath("/pulls/%d", existing.Number), body, &result); err != nil {
+				return nil, fmt.Errorf("failed to update pull request #%d. %v", existing.Number, err)
+			}
+			return g.pullRequest(result), nil
+		}
+	}
+
+	body["head"], body["base"] = pr.Head, pr.Base
+	if err := g.api.do(http.MethodPost, g.repoPath("/pulls"), body, &result); err != nil {
+		return nil, fmt.Errorf("failed to create pull request for %s. %v", pr.Head, err)
+	}
+
+	return g.pullRequest(result), nil
+}
+
+func (g *Gitea) release(r giteaRelease) *Release {
+	release := &Release{
+		ID: r.ID,
+		Tag: r.TagName,
+		Name: r.Name,
+		Body: r.Body,
+		URL: r.HTMLURL,
+		PreRelease: r.PreRelease,
+	}
+
+	for _, a := range r.Assets {
+		release.Assets = append(release.Assets, Asset{Name: a.Name, URL: a.BrowserDownloadURL})
+	}
+
+	return release
+}
+
+func (g *Gitea) CreateRelease(release Release) (*Release, error) {
+	var created giteaRelease
+	body := map[string]interface{}{"tag_name": release.Tag, "name": release.Name, "body": release.Body, "prerelease": release.PreRelease}
+	if err := g.api.do(http.MethodPost, g.repoPath("/releases"), body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create release %s. %v", release.Tag, err)
+	}
+
+	return g.release(created), nil
+}
+
+func (g *Gitea) GetRelease(tag string) (*Release, error) {
+	var found giteaRelease
+	if err := g.api.do(http.MethodGet, g.repoPath("/releases/tags/%s", url.PathEscape(tag)), nil, &found); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get release %s. %v", tag, err)
+	}
+
+	return g.release(found), nil
+}
+
+func (g *Gitea) UpdateRelease(release Release) (*Release, error) {
+	var updated giteaRelease
+	body := map[string]interface{}{"name": release.Name, "body": release.Body, "prerelease": release.PreRelease}
+	if err := g.api.do(http.MethodPatch, g.repoPath("/releases/%d", release.ID), body, &updated); err != nil {
+		return nil, fmt.Errorf("failed to update release %s. %v", release.Tag, err)
+	}
+
+	return g.release(updated), nil
+}
+
+func (g *Gitea) UploadAsset(release *Release, path string) (*Asset, error) {
+	var asset giteaAsset
+	query := url.Values{"name": {filepath.Base(path)}}
+	if err := g.api.upload(g.repoPath("/releases/%d/assets?%s", release.ID, query.Encode()), "attachment", path, &asset); err != nil {
+		return nil, fmt.Errorf("failed to upload %s to release %s. %v", path, release.Tag, err)
+	}
+
+	return &Asset{Name: asset.Name, URL: asset.BrowserDownloadURL}, nil
+}
+
+func (g *Gitea) ListReleases() ([]Release, error) {
+	var releases []giteaRelease
+	if err := g.api.do(http.MethodGet, g.repoPath("/releases?limit=50"), nil, &releases); err != nil {
+		return nil, fmt.Errorf("failed to list releases. %v", err)
+	}
+
+	var result []Release
+	for _, r := range releases {
+		result = append(result, *g.release(r))
+	}
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package forge
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func giteaPR(number int, head, base string) giteaPullRequest {
+	pr := giteaPullRequest{Number: number, HTMLURL: "https://git.example.com/team/app/pulls/1", Title: "ABC-1 the feature"}
+	pr.Head.Ref, pr.Base.Ref = head, base
+	return pr
+}
+
+func TestGiteaCreatePullRequest(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/team/app/pulls": respond(http.StatusOK, []giteaPullRequest{giteaPR(3, "ABC-2", "main")}),
+		"POST /api/v1/repos/team/app/pulls": respond(http.StatusCreated, giteaPR(4, "ABC-1", "main")),
+	})
+
+	pr, err := NewGitea(f.URL, "secret", "team", "app").CreatePullRequest(PullRequest{Title: "ABC-1 the feature", Body: "notes", Head: "ABC-1", Base: "main"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed. %v", err)
+	}
+
+	if pr.Number != 4 || pr.Head != "ABC-1" || pr.Base != "main" {
+		t.Errorf("unexpected pull request %+v", pr)
+	}
+	if body := f.body("POST /api/v1/repos/team/app/pulls"); body["head"] != "ABC-1" || body["base"] != "main" || body["body"] != "notes" {
+		t.Errorf("unexpected pull request body %v", body)
+	}
+	if got := f.header("POST /api/v1/repos/team/app/pulls", "Authorization"); got != "token secret" {
+		t.Errorf("expected the token in the Authorization header, got '%s'", got)
+	}
+}
+
+// rerunning finish after the pull request was opened updates it rather than opening another
+func TestGiteaCreatePullRequestRerun(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/team/app/pulls": respond(http.StatusOK, []giteaPullRequest{giteaPR(4, "ABC-1", "main")}),
+		"PATCH /api/v1/repos/team/app/pulls/4": respond(http.StatusOK, giteaPR(4, "ABC-1", "main")),
+	})
+
+	pr, err := NewGitea(f.URL, "", "team", "app").CreatePullRequest(PullRequest{Title: "ABC-1 the feature", Body: "new notes", Head: "ABC-1", Base: "main"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed. %v", err)
+	}
+
+	if pr.Number != 4 {
+		t.Errorf("expected the existing pull request, got #%d", pr.Number)
+	}
+	if f.called("POST /api/v1/repos/team/app/pulls") {
+		t.Error("CreatePullRequest should not open a second pull request for the same branches")
+	}
+	if body := f.body("PATCH /api/v1/repos/team/app/pulls/4"); body["body"] != "new notes" {
+		t.Errorf("unexpected pull request update %v", body)
+	}
+}
+
+func TestGiteaCreatePullRequestError(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v1/repos/team/app/pulls": respond(http.StatusOK, []giteaPullRequest{}),
+		"POST /api/v1/repos/team/app/pulls": respond(http.StatusUnprocessableEntity, map[string]string{"message": "head branch does not exist"}),
+	})
+
+	_, err := NewGitea(f.URL, "", "team", "app").CreatePullRequest(PullRequest{Head: "ABC-1", Base: "main"})
+	if err == nil {
+		t.Fatal("CreatePullRequest should fail when the forge rejects the pull request")
+	}
+	if !strings.Contains(err.Error(), "422") || !strings.Contains(err.Error(), "head branch does not exist") {
+		t.Errorf("error should hold the status and message of the forge. got: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v43/github"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+var ctx = context.Background()
+
+type tokenTransport struct {
+	token string
+	header string
+	prefix string
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.header, t.prefix + t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func httpClient(token, header, prefix string) *http.Client {
+	if token == "" {
+		return http.DefaultClient
+	}
+	return &http.Client{Transport: &tokenTransport{token: token, header: header, prefix: prefix}}
+}
+
+type GitHub struct {
+	client *github.Client
+	owner string
+	repo string
+}
+
+// NewGitHub creates a GitHub forge. a base url selects a GitHub Enterprise (or compatible) API instead of api.github.com
+func NewGitHub(baseURL, token, owner, repo string) (*GitHub, error) {
+	client := github.NewClient(httpClient(token, "Authorization", "token "))
+
+	if baseURL != "" {
+		var err error
+		client, err = github.NewEnterpriseClient(baseURL, baseURL, httpClient(token, "Authorization", "token "))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitHub client for %s. %v", baseURL, err)
+		}
+	}
+
+	return &GitHub{client: client, owner: owner, repo: repo}, nil
+}
+
+func (g *GitHub) Name() string {
+	return "github"
+}
+
+// CreatePullRequest opens a pull request, or updates the title and body of an open one for the same branches
+func (g *GitHub) CreatePullRequest(pr PullRequest) (*PullRequest, error) {
+	existing, _, err := g.client.PullRequests.List(ctx, g.owner, g.repo, &github.PullRequestListOptions{
+		State: "open",
+		Head: g.owner + ":" + pr.Head,
+		Base: pr.Base,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests for %s/%s. %v", g.owner, g.repo, err)
+	}
+
+	if len(existing) > 0 {
+		logging.Instance().Debugf("updating existing pull request #%d for %s", existing[0].GetNumber(), pr.Head)
+
+		updated, _, err := g.client.PullRequests.Edit(ctx, g.owner, g.repo, existing[0].GetNumber(), &github.PullRequest{
+			Title: github.String(pr.Title),
+			Body: github.String(pr.Body),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update pull request #%d. %v", existing[0].GetNumber(), err)
+		}
+		return githubPullRequest(updated), nil
+	}
+
+	created, _, err := g.client.PullRequests.Create(ctx, g.owner, g.repo, &github.NewPullRequest{
+		Title: github.String(pr.Title),
+		Body: github.String(pr.Body),
+		Head: github.String(pr.Head),
+		Base: github.String(pr.Base),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request for %s. %v", pr.Head, err)
+	}
+
+	logging.Instance().Debugf("created pull request #%d for %s", created.GetNumber(), pr.Head)
+
+	return githubPullRequest(created), nil
+}
+
+func (g *GitHub) CreateRelease(release Release) (*Release, error) {
+	created, _, err := g.client.Repositories.CreateRelease(ctx, g.owner, g.repo, &github.RepositoryRelease{
+		TagName: github.String(release.Tag),
+		Name: github.String(release.Name),
+		Body: github.String(release.Body),
+		Prerelease: github.Bool(release.PreRelease),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create release %s. %v", release.Tag, err)
+	}
+
+	logging.Instance().Debugf("created release %s (id: %d)", release.Tag, created.GetID())
+
+	return githubRelease(created), nil
+}
+
+func (g *GitHub) GetRelease(tag string) (*Release, error) {
+	found, resp, err := g.client.Repositories.GetReleaseByTag(ctx, g.owner, g.repo, tag)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get release %s. %v", tag, err)
+	}
+
+	return githubRelease(found), nil
+}
+
+func (g *GitHub) UpdateRelease(release Release) (*Release, error) {
+	updated, _, err := g.client.Repositories.EditRelease(ctx, g.owner, g.repo, release.ID, &github.RepositoryRelease{
+		Name: github.String(release.Name),
+		Body: github.String(release.Body),
+		Prerelease: github.Bool(release.PreRelease),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update release %s. %v", release.Tag, err)
+	}
+
+	logging.Instance().Debugf("updated release %s (id: %d)", release.Tag, updated.GetID())
+
+	return githubRelease(updated), nil
+}
+
+func (g *GitHub) UploadAsset(release *Release, path string) (*Asset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	asset, _, err := g.client.Repositories.UploadReleaseAsset(ctx, g.owner, g.repo, release.ID, &github.UploadOptions{Name: filepath.Base(path)}, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %s to release %s. %v", path, release.Tag, err)
+	}
+
+	return &Asset{Name: asset.GetName(), URL: asset.GetBrowserDownloadURL()}, nil
+}
+
+func (g *GitHub) ListReleases() ([]Release, error) {
+	var releases []Release
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := g.client.Repositories.ListReleases(ctx, g.owner, g.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases for %s/%s. %v", g.owner, g.repo, err)
+		}
+
+		for _, r := range page {
+			releases = append(releases, *githubRelease(r))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return releases, nil
+}
+
+func githubRelease(r *github.RepositoryRelease) *Release {
+	release := &Release{
+		ID: r.GetID(),
+		Tag: r.GetTagName(),
+		Name: r.GetName(),
+		Body: r.GetBody(),
+		URL: r.GetHTMLURL(),
+		PreRelease: r.GetPrerelease(),
+	}
+
+	for _, a := range r.Assets {
+		release.Assets = append(release.Assets, Asset{Name: a.GetName(), URL: a.GetBrowserDownloadURL()})
+	}
+
+	return release
+}
+
+func githubPullRequest(pr *github.PullRequest) *PullRequest {
+	return &PullRequest{
+		Number: pr.GetNumber(),
+		URL: pr.GetHTMLURL(),
+		Title: pr.GetTitle(),
+		Body: pr.GetBody(),
+		Head: pr.GetHead().GetRef(),
+		Base: pr.GetBase().GetRef(),
+	}
+}
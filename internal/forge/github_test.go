@@ -0,0 +1,137 @@
+package forge
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// github pull requests and releases as the API returns them. the enterprise client serves them under /api/v3
+type githubRef struct {
+	Ref string `json:"ref"`
+}
+
+type githubPR struct {
+	Number int `json:"number"`
+	Head githubRef `json:"head"`
+	Base githubRef `json:"base"`
+}
+
+type githubReleaseJSON struct {
+	ID int64 `json:"id"`
+	TagName string `json:"tag_name"`
+	Body string `json:"body"`
+	Assets []map[string]string `json:"assets"`
+}
+
+func newTestGitHub(t *testing.T, f *fakeForge) *GitHub {
+	g, err := NewGitHub(f.URL, "secret", "team", "app")
+	if err != nil {
+		t.Fatalf("NewGitHub failed. %v", err)
+	}
+	return g
+}
+
+func TestGitHubCreatePullRequest(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v3/repos/team/app/pulls": respond(http.StatusOK, []githubPR{}),
+		"POST /api/v3/repos/team/app/pulls": respond(http.StatusCreated, githubPR{Number: 9, Head: githubRef{"ABC-1"}, Base: githubRef{"main"}}),
+	})
+
+	pr, err := newTestGitHub(t, f).CreatePullRequest(PullRequest{Title: "ABC-1 the feature", Body: "notes", Head: "ABC-1", Base: "main"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed. %v", err)
+	}
+
+	if pr.Number != 9 || pr.Head != "ABC-1" || pr.Base != "main" {
+		t.Errorf("unexpected pull request %+v", pr)
+	}
+	if body := f.body("POST /api/v3/repos/team/app/pulls"); body["head"] != "ABC-1" || body["body"] != "notes" {
+		t.Errorf("unexpected pull request body %v", body)
+	}
+	if got := f.header("POST /api/v3/repos/team/app/pulls", "Authorization"); got != "token secret" {
+		t.Errorf("expected the token in the Authorization header, got '%s'", got)
+	}
+}
+
+func TestGitHubCreatePullRequestRerun(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v3/repos/team/app/pulls": respond(http.StatusOK, []githubPR{{Number: 9, Head: githubRef{"ABC-1"}, Base: githubRef{"main"}}}),
+		"PATCH /api/v3/repos/team/app/pulls/9": respond(http.StatusOK, githubPR{Number: 9, Head: githubRef{"ABC-1"}, Base: githubRef{"main"}}),
+	})
+
+	pr, err := newTestGitHub(t, f).CreatePullRequest(PullRequest{Title: "ABC-1", Body: "new notes", Head: "ABC-1", Base: "main"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed. %v", err)
+	}
+
+	if pr.Number != 9 || f.called("POST /api/v3/repos/team/app/pulls") {
+		t.Error("CreatePullRequest should update the open pull request rather than open another")
+	}
+	if body := f.body("PATCH /api/v3/repos/team/app/pulls/9"); body["body"] != "new notes" {
+		t.Errorf("unexpected pull request update %v", body)
+	}
+}
+
+func TestGitHubCreatePullRequestError(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v3/repos/team/app/pulls": respond(http.StatusOK, []githubPR{}),
+		"POST /api/v3/repos/team/app/pulls": respond(http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"}),
+	})
+
+	_, err := newTestGitHub(t, f).CreatePullRequest(PullRequest{Head: "ABC-1", Base: "main"})
+	if err == nil || !strings.Contains(err.Error(), "422") {
+		t.Errorf("CreatePullRequest should fail with the status of the forge. got: %v", err)
+	}
+}
+
+func TestGitHubPublishRelease(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v3/repos/team/app/releases/tags/v1.2.0": respond(http.StatusNotFound, map[string]string{"message": "Not Found"}),
+		"POST /api/v3/repos/team/app/releases": respond(http.StatusCreated, githubReleaseJSON{ID: 11, TagName: "v1.2.0", Body: "notes"}),
+		"POST /api/uploads/repos/team/app/releases/11/assets": respond(http.StatusCreated, map[string]string{"name": "app.tar.gz"}),
+	})
+
+	published, err := PublishRelease(newTestGitHub(t, f), Release{Tag: "v1.2.0", Name: "v1.2.0", Body: "notes", PreRelease: true}, []string{writeAsset(t, "app.tar.gz")})
+	if err != nil {
+		t.Fatalf("PublishRelease failed. %v", err)
+	}
+
+	if published.ID != 11 || len(published.Assets) != 1 {
+		t.Errorf("unexpected published release %+v", published)
+	}
+	if body := f.body("POST /api/v3/repos/team/app/releases"); body["tag_name"] != "v1.2.0" || body["prerelease"] != true {
+		t.Errorf("unexpected release body %v", body)
+	}
+}
+
+func TestGitHubPublishReleaseRerun(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v3/repos/team/app/releases/tags/v1.2.0": respond(http.StatusOK, githubReleaseJSON{ID: 11, TagName: "v1.2.0", Body: "old", Assets: []map[string]string{{"name": "app.tar.gz"}}}),
+		"PATCH /api/v3/repos/team/app/releases/11": respond(http.StatusOK, githubReleaseJSON{ID: 11, TagName: "v1.2.0", Body: "notes"}),
+	})
+
+	published, err := PublishRelease(newTestGitHub(t, f), Release{Tag: "v1.2.0", Name: "v1.2.0", Body: "notes"}, []string{writeAsset(t, "app.tar.gz")})
+	if err != nil {
+		t.Fatalf("PublishRelease failed. %v", err)
+	}
+
+	if f.called("POST /api/v3/repos/team/app/releases") || f.called("POST /api/uploads/repos/team/app/releases/11/assets") {
+		t.Error("PublishRelease should neither create the release again nor upload an asset it already has")
+	}
+	if published.Body != "notes" || len(published.Assets) != 1 {
+		t.Errorf("unexpected published release %+v", published)
+	}
+}
+
+func TestGitHubPublishReleaseError(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET /api/v3/repos/team/app/releases/tags/v1.2.0": respond(http.StatusNotFound, map[string]string{"message": "Not Found"}),
+		"POST /api/v3/repos/team/app/releases": respond(http.StatusUnauthorized, map[string]string{"message": "Bad credentials"}),
+	})
+
+	_, err := PublishRelease(newTestGitHub(t, f), Release{Tag: "v1.2.0"}, []string{writeAsset(t, "app.tar.gz")})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("PublishRelease should fail with the status of the forge. got: %v", err)
+	}
+}
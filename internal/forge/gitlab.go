@@ -0,0 +1,190 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"sykesdev.ca/gog/internal/logging"
+)
+
+type GitLab struct {
+	api *apiClient
+	webURL string
+	path string
+	project string
+}
+
+type gitlabMergeRequest struct {
+	IID int `json:"iid"`
+	WebURL string `json:"web_url"`
+	Title string `json:"title"`
+	Description string `json:"description"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+}
+
+type gitlabRelease struct {
+	TagName string `json:"tag_name"`
+	Name string `json:"name"`
+	Description string `json:"description"`
+	UpcomingRelease bool `json:"upcoming_release"`
+	Links struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+	Assets struct {
+		Links []gitlabLink `json:"links"`
+	} `json:"assets"`
+}
+
+type gitlabLink struct {
+	Name string `json:"name"`
+	URL string `json:"url"`
+}
+
+// NewGitLab creates a GitLab forge. a base url selects a self-hosted instance instead of gitlab.com
+func NewGitLab(baseURL, token, owner, repo string) *GitLab {
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	return &GitLab{
+		api: newAPIClient(baseURL + "/api/v4", httpClient(token, "PRIVATE-TOKEN", "")),
+		webURL: baseURL,
+		path: owner + "/" + repo,
+		project: url.PathEscape(owner + "/" + repo),
+	}
+}
+
+func (g *GitLab) Name() string {
+	return "gitlab"
+}
+
+func (g *GitLab) projectPath(format string, args ...interface{}) string {
+	return "/projects/" + g.project + fmt.Sprintf(format, args...)
+}
+
+// CreatePullRequest opens a merge request, or updates the title and description of an open one for the same branches
+func (g *GitLab) CreatePullRequest(pr PullRequest) (*PullRequest, error) {
+	var existing []gitlabMergeRequest
+	query := url.Values{"state": {"opened"}, "source_branch": {pr.Head}, "target_branch": {pr.Base}}
+	if err := g.api.do(http.MethodGet, g.projectPath("/merge_requests?%s", query.Encode()), nil, &existing); err != nil {
+		return nil, fmt.Errorf("failed to list merge requests. %v", err)
+	}
+
+	body := map[string]string{"title": pr.Title, "description": pr.Body}
+
+	var mr gitlabMergeRequest
+	if len(existing) > 0 {
+		logging.Instance().Debugf("updating existing merge request !%d for %s", existing[0].IID, pr.Head)
+
+		if err := g.api.do(http.MethodPut, g.projectPath("/merge_requests/%d", existing[0].IID), body, &mr); err != nil {
+			return nil, fmt.Errorf("failed to update merge request !%d. %v", existing[0].IID, err)
+		}
+	} else {
+		body["source_branch"], body["target_branch"] = pr.Head, pr.Base
+
+		if err := g.api.do(http.MethodPost, g.projectPath("/merge_requests"), body, &mr); err != nil {
+			return nil, fmt.Errorf("failed to create merge request for %s. %v", pr.Head, err)
+		}
+	}
+
+	return &PullRequest{
+		Number: mr.IID,
+		URL: mr.WebURL,
+		Title: mr.Title,
+		Body: mr.Description,
+		Head: mr.SourceBranch,
+		Base: mr.TargetBranch,
+	}, nil
+}
+
+func (g *GitLab) release(r gitlabRelease) *Release {
+	release := &Release{
+		Tag: r.TagName,
+		Name: r.Name,
+		Body: r.Description,
+		URL: r.Links.Self,
+		PreRelease: r.UpcomingRelease,
+	}
+
+	for _, l := range r.Assets.Links {
+		release.Assets = append(release.Assets, Asset{Name: l.Name, URL: l.URL})
+	}
+
+	return release
+}
+
+func (g *GitLab) CreateRelease(release Release) (*Release, error) {
+	var created gitlabRelease
+	body := map[string]string{"tag_name": release.Tag, "name": release.Name, "description": release.Body}
+	if err := g.api.do(http.MethodPost, g.projectPath("/releases"), body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create release %s. %v", release.Tag, err)
+	}
+
+	return g.release(created), nil
+}
+
+func (g *GitLab) GetRelease(tag string) (*Release, error) {
+	var found gitlabRelease
+	if err := g.api.do(http.MethodGet, g.projectPath("/releases/%s", url.PathEscape(tag)), nil, &found); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get release %s. %v", tag, err)
+	}
+
+	return g.release(found), nil
+}
+
+// UpdateRelease updates the name and notes of the release for the tag. GitLab has no pre-release flag to update
+func (g *GitLab) UpdateRelease(release Release) (*Release, error) {
+	var updated gitlabRelease
+	body := map[string]string{"name": release.Name, "description": release.Body}
+	if err := g.api.do(http.MethodPut, g.projectPath("/releases/%s", url.PathEscape(release.Tag)), body, &updated); err != nil {
+		return nil, fmt.Errorf("failed to update release %s. %v", release.Tag, err)
+	}
+
+	return g.release(updated), nil
+}
+
+// UploadAsset uploads the file to the project and links it to the release
+func (g *GitLab) UploadAsset(release *Release, path string) (*Asset, error) {
+	var uploaded struct {
+		URL string `json:"url"`
+		FullPath string `json:"full_path"`
+	}
+	if err := g.api.upload(g.projectPath("/uploads"), "file", path, &uploaded); err != nil {
+		return nil, fmt.Errorf("failed to upload %s. %v", path, err)
+	}
+
+	assetURL := g.webURL + uploaded.FullPath
+	if uploaded.FullPath == "" {
+		assetURL = g.webURL + "/" + g.path + uploaded.URL
+	}
+
+	var link gitlabLink
+	name := filepath.Base(path)
+	body := map[string]string{"name": name, "url": assetURL}
+	if err := g.api.do(http.MethodPost, g.projectPath("/releases/%s/assets/links", url.PathEscape(release.Tag)), body, &link); err != nil {
+		return nil, fmt.Errorf("failed to link %s to release %s. %v", name, release.Tag, err)
+	}
+
+	return &Asset{Name: link.Name, URL: link.URL}, nil
+}
+
+func (g *GitLab) ListReleases() ([]Release, error) {
+	var releases []gitlabRelease
+	if err := g.api.do(http.MethodGet, g.projectPath("/releases?per_page=100"), nil, &releases); err != nil {
+		return nil, fmt.Errorf("failed to list releases. %v", err)
+	}
+
+	var result []Release
+	for _, r := range releases {
+		result = append(result, *g.release(r))
+	}
+	return result, nil
+}
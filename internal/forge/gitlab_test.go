@@ -0,0 +1,118 @@
+package forge
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const gitlabProject = "/api/v4/projects/group%2Fapp"
+
+func TestGitLabCreatePullRequest(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET " + gitlabProject + "/merge_requests": respond(http.StatusOK, []gitlabMergeRequest{}),
+		"POST " + gitlabProject + "/merge_requests": respond(http.StatusCreated, gitlabMergeRequest{IID: 5, SourceBranch: "ABC-1", TargetBranch: "main"}),
+	})
+
+	pr, err := NewGitLab(f.URL, "secret", "group", "app").CreatePullRequest(PullRequest{Title: "ABC-1 the feature", Body: "notes", Head: "ABC-1", Base: "main"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest failed. %v", err)
+	}
+
+	if pr.Number != 5 || pr.Head != "ABC-1" {
+		t.Errorf("unexpected merge request %+v", pr)
+	}
+	body := f.body("POST " + gitlabProject + "/merge_requests")
+	if body["source_branch"] != "ABC-1" || body["target_branch"] != "main" || body["description"] != "notes" {
+		t.Errorf("unexpected merge request body %v", body)
+	}
+	if got := f.header("POST " + gitlabProject + "/merge_requests", "PRIVATE-TOKEN"); got != "secret" {
+		t.Errorf("expected the token in the PRIVATE-TOKEN header, got '%s'", got)
+	}
+}
+
+func TestGitLabCreatePullRequestRerun(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET " + gitlabProject + "/merge_requests": respond(http.StatusOK, []gitlabMergeRequest{{IID: 5, SourceBranch: "ABC-1", TargetBranch: "main"}}),
+		"PUT " + gitlabProject + "/merge_requests/5": respond(http.StatusOK, gitlabMergeRequest{IID: 5, SourceBranch: "ABC-1", TargetBranch: "main"}),
+	})
+
+	if _, err := NewGitLab(f.URL, "", "group", "app").CreatePullRequest(PullRequest{Title: "ABC-1", Body: "new notes", Head: "ABC-1", Base: "main"}); err != nil {
+		t.Fatalf("CreatePullRequest failed. %v", err)
+	}
+
+	if f.called("POST " + gitlabProject + "/merge_requests") {
+		t.Error("CreatePullRequest should not open a second merge request for the same branches")
+	}
+	if body := f.body("PUT " + gitlabProject + "/merge_requests/5"); body["description"] != "new notes" {
+		t.Errorf("unexpected merge request update %v", body)
+	}
+}
+
+func TestGitLabCreatePullRequestError(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET " + gitlabProject + "/merge_requests": respond(http.StatusUnauthorized, map[string]string{"message": "401 Unauthorized"}),
+	})
+
+	_, err := NewGitLab(f.URL, "bad", "group", "app").CreatePullRequest(PullRequest{Head: "ABC-1", Base: "main"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("CreatePullRequest should fail with the status of the forge. got: %v", err)
+	}
+}
+
+func TestGitLabPublishRelease(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET " + gitlabProject + "/releases/v1.2.0": respond(http.StatusNotFound, map[string]string{"message": "404 Not Found"}),
+		"POST " + gitlabProject + "/releases": respond(http.StatusCreated, gitlabRelease{TagName: "v1.2.0", Name: "v1.2.0", Description: "notes"}),
+		"POST " + gitlabProject + "/uploads": respond(http.StatusCreated, map[string]string{"url": "/uploads/abc/app.tar.gz", "full_path": "/group/app/uploads/abc/app.tar.gz"}),
+		"POST " + gitlabProject + "/releases/v1.2.0/assets/links": respond(http.StatusCreated, gitlabLink{Name: "app.tar.gz", URL: "https://gitlab.example.com/group/app/uploads/abc/app.tar.gz"}),
+	})
+
+	published, err := PublishRelease(NewGitLab(f.URL, "", "group", "app"), Release{Tag: "v1.2.0", Name: "v1.2.0", Body: "notes"}, []string{writeAsset(t, "app.tar.gz")})
+	if err != nil {
+		t.Fatalf("PublishRelease failed. %v", err)
+	}
+
+	if len(published.Assets) != 1 || published.Assets[0].Name != "app.tar.gz" {
+		t.Errorf("unexpected release assets %+v", published.Assets)
+	}
+	if body := f.body("POST " + gitlabProject + "/releases/v1.2.0/assets/links"); body["url"] != f.URL + "/group/app/uploads/abc/app.tar.gz" {
+		t.Errorf("the asset should link to the uploaded file, sent %v", body)
+	}
+}
+
+func TestGitLabPublishReleaseRerun(t *testing.T) {
+	existing := gitlabRelease{TagName: "v1.2.0", Name: "v1.2.0", Description: "old"}
+	existing.Assets.Links = []gitlabLink{{Name: "app.tar.gz"}}
+
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET " + gitlabProject + "/releases/v1.2.0": respond(http.StatusOK, existing),
+		"PUT " + gitlabProject + "/releases/v1.2.0": respond(http.StatusOK, gitlabRelease{TagName: "v1.2.0", Name: "v1.2.0", Description: "notes"}),
+	})
+
+	published, err := PublishRelease(NewGitLab(f.URL, "", "group", "app"), Release{Tag: "v1.2.0", Name: "v1.2.0", Body: "notes"}, []string{writeAsset(t, "app.tar.gz")})
+	if err != nil {
+		t.Fatalf("PublishRelease failed. %v", err)
+	}
+
+	if f.called("POST " + gitlabProject + "/releases") || f.called("POST " + gitlabProject + "/uploads") {
+		t.Error("PublishRelease should neither create the release again nor upload an asset it already has")
+	}
+	if published.Body != "notes" || len(published.Assets) != 1 {
+		t.Errorf("unexpected published release %+v", published)
+	}
+}
+
+func TestGitLabPublishReleaseError(t *testing.T) {
+	f := newFakeForge(t, map[string]http.HandlerFunc{
+		"GET " + gitlabProject + "/releases/v1.2.0": respond(http.StatusInternalServerError, map[string]string{"message": "500 Internal Server Error"}),
+	})
+
+	_, err := PublishRelease(NewGitLab(f.URL, "", "group", "app"), Release{Tag: "v1.2.0"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("PublishRelease should fail with the status of the forge. got: %v", err)
+	}
+	if f.called("POST " + gitlabProject + "/releases") {
+		t.Error("PublishRelease should not create a release when the existing one cannot be read")
+	}
+}
@@ -0,0 +1,183 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Commit struct {
+	Hash string `json:"hash"`
+	ShortHash string `json:"short_hash"`
+	Author string `json:"author"`
+	Subject string `json:"subject"`
+	Body string `json:"body"`
+}
+
+// Tag is an annotated or lightweight tag. lightweight tags have the message and date of the commit they point at
+type Tag struct {
+	Name string `json:"name"`
+	Message string `json:"message"`
+	Date time.Time `json:"date"`
+}
+
+// Signing is how tags are signed. tags are only signed with Sign set, using git's own gpg.format and user.signingkey unless Format or Key are given
+type Signing struct {
+	Sign bool `json:"sign,omitempty"`
+	// Format is gpg or ssh
+	Format string `json:"format,omitempty"`
+	// Key is a GPG key id, or the path to an SSH key
+	Key string `json:"key,omitempty"`
+}
+
+// Signature is the result of verifying a tag. unsigned tags have no Format
+type Signature struct {
+	Format string `json:"format,omitempty"`
+	Valid bool `json:"valid"`
+	Signer string `json:"signer,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (s Signature) Signed() bool {
+	return s.Format != ""
+}
+
+// tagArgs are the arguments of 'git tag' creating an annotated, optionally signed, tag
+func (s Signing) tagArgs(name, message string, force bool) []string {
+	var args []string
+	if s.Sign && s.Format != "" {
+		args = append(args, "-c", "gpg.format=" + signingFormats[s.Format])
+	}
+
+	args = append(args, "tag")
+	if s.Sign {
+		args = append(args, "-s")
+		if s.Key != "" {
+			args = append(args, "-u", s.Key)
+		}
+	} else {
+		args = append(args, "-a")
+	}
+
+	args = append(args, name)
+	if force {
+		args = append(args, "--force")
+	}
+
+	// git strips lines starting with '#' from tag messages by default, which would drop the headings of a changelog entry
+	if commentLine.MatchString(message) {
+		args = append(args, "--cleanup=whitespace")
+	}
+	return append(args, "-m", message)
+}
+
+var commentLine = regexp.MustCompile(`(?m)^#`)
+
+// signingFormats map the signing formats GOG accepts to git's gpg.format
+var signingFormats = map[string]string{
+	"gpg": "openpgp",
+	"ssh": "ssh",
+}
+
+// signatureMarkers identify the format of the signature appended to a tag message
+var signatureMarkers = map[string]string{
+	"-----BEGIN PGP SIGNATURE-----": "gpg",
+	"-----BEGIN SSH SIGNATURE-----": "ssh",
+	"-----BEGIN SIGNED MESSAGE-----": "x509",
+}
+
+func (c Commit) Message() string {
+	if c.Body == "" {
+		return c.Subject
+	}
+	return c.Subject + "\n\n" + c.Body
+}
+
+func (c Commit) String() string {
+	return fmt.Sprintf("`%s` - %s", c.ShortHash, c.Subject)
+}
+
+type Backend interface {
+	IsRepository() bool
+	ProjectRoot() (string, error)
+	GitDir() (string, error)
+	CurrentBranch() (string, error)
+	DefaultBranch() (string, error)
+	LocalBranchExists(name string) bool
+	RemoteBranchExists(name string) bool
+	UncommittedChanges() bool
+	ConflictedFiles() ([]string, error)
+	Head() (string, error)
+	Commits(max int) ([]Commit, error)
+	// CommitRange lists the first-parent commits reachable from to but not from, newest first. an empty from lists the whole history of to
+	CommitRange(from, to string) ([]Commit, error)
+	MergedTags(branch string) ([]string, error)
+	LatestTag() (string, error)
+	TagDetails(name string) (Tag, error)
+	RevParse(ref string) (string, error)
+	UntrackedFiles() ([]string, error)
+	RebaseInProgress() bool
+	RemoteURL() (string, error)
+	// AheadBehind counts the commits on the local branch missing from origin, and those on origin missing locally
+	AheadBehind(branch string) (int, int, error)
+	// ChangedFiles lists the paths, relative to the project root, changed on HEAD since it forked from base
+	ChangedFiles(base string) ([]string, error)
+
+	Checkout(name string, create bool) error
+	DeleteLocalBranch(name string) error
+	DeleteRemoteBranch(name string) error
+	Stage(paths ...string) error
+	StageAll() error
+	Commit(message string) error
+	Fetch() error
+	Pull() error
+	Push(branch string, setUpstream bool) error
+	ForcePush(branch string) error
+	PushTags() error
+	Rebase(onto string) error
+	RebaseOnto(onto, upstream string) error
+	ContinueRebase() error
+	SquashMerge(branch string) error
+	CreateTag(name, message string, force bool, signing Signing) error
+	// VerifyTag checks the signature of a tag. unsigned tags are not an error
+	VerifyTag(name string) (Signature, error)
+	DeleteTag(name string) error
+	ResetHard(ref string) error
+	CheckoutAt(name, commit string) error
+	UpdateRef(ref, object string) error
+	AbortRebase() error
+	StashCreate() (string, error)
+	StashApply(ref string) error
+}
+
+var (
+	backendMu sync.Mutex
+	defaultBackend Backend
+)
+
+func DefaultBackend() Backend {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+
+	if defaultBackend == nil {
+		defaultBackend = NewExecBackend("")
+	}
+
+	return defaultBackend
+}
+
+func SetDefaultBackend(b Backend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+
+	defaultBackend = b
+}
+
+func validRefName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, " ~^:?*[\\\x00") || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid git reference name: '%s'", name)
+	}
+	return nil
+}
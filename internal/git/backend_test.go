@@ -0,0 +1,57 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestValidRefName(t *testing.T) {
+	tests := []struct {
+		name string
+		valid bool
+	}{
+		{"ABC-123", true},
+		{"feature/login", true},
+		{"v1.2.0", true},
+		{"", false},
+		{"--upload-pack=touch /tmp/pwned", false},
+		{"-f", false},
+		{"ABC-1 --force", false},
+		{"main..evil", false},
+		{"HEAD~1", false},
+		{"HEAD^", false},
+		{"refs:heads", false},
+		{"what?", false},
+		{"glob*", false},
+		{"[abc]", false},
+		{"back\\slash", false},
+		{"nul\x00byte", false},
+	}
+
+	for _, tt := range tests {
+		if err := validRefName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("validRefName(%q) = %v, want valid: %t", tt.name, err, tt.valid)
+		}
+	}
+}
+
+// every backend refuses names which git would read as options, so a ticket can never inject arguments
+func TestBackendsRejectInjectedNames(t *testing.T) {
+	m := NewMemoryBackend("/work/app", "main")
+	backends := map[string]Backend{
+		"memory": m,
+		"recording": NewRecordingBackend(m),
+	}
+
+	for name, b := range backends {
+		if err := b.Checkout("--orphan=evil", true); err == nil {
+			t.Errorf("%s backend checked out an option-like branch", name)
+		}
+		if err := b.CreateTag("-d", "message", false, Signing{}); err == nil {
+			t.Errorf("%s backend created an option-like tag", name)
+		}
+	}
+
+	if m.LocalBranchExists("--orphan=evil") {
+		t.Error("the memory backend should not have created the rejected branch")
+	}
+}
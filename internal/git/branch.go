@@ -1,10 +1,9 @@
 package git
 
 import (
-	"fmt"
-	"os/exec"
+	"regexp"
+	"strings"
 
-	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/logging"
 )
 
@@ -12,34 +11,102 @@ type Branch struct {
 	Name string `json:"name"`
 	RemoteExists bool `json:"remote_exists"`
 	LocalExists bool `json:"local_exists"`
+
+	backend Backend
 }
 
 func NewBranch(name string) *Branch {
+	return newBranch(DefaultBackend(), name)
+}
+
+func newBranch(backend Backend, name string) *Branch {
 	b := &Branch{
 		Name: name,
+		backend: backend,
 	}
-	b.RemoteExists = remoteBranchExists(b)
-	b.LocalExists = localBranchExists(b)
+	b.RemoteExists = remoteBranchExists(backend, b)
+	b.LocalExists = localBranchExists(backend, b)
 
 	return b
 }
 
+func (b *Branch) git() Backend {
+	if b.backend == nil {
+		return DefaultBackend()
+	}
+	return b.backend
+}
+
 func (b *Branch) UncommittedChanges() bool {
-	cmd := exec.Command("bash", "-c", "git status --porcelain | egrep '^[A,M,D,R]'")
-	_, err := cmd.Output()
+	changes := b.git().UncommittedChanges()
+
+	logging.Instance().Debugf("uncommitted changes: %t", changes)
 
-	logging.Instance().Debugf("uncommitted changes: %t", err == nil)
+	return changes
+}
 
-	return err == nil
+// AheadBehind reports how many commits the branch is ahead of and behind its counterpart on origin
+func (b *Branch) AheadBehind() (int, int, error) {
+	return b.git().AheadBehind(b.Name)
+}
+
+// RelatedCommits lists the commits made on the branch since it forked from the default branch whose subject mentions the branch's ticket
+func (b *Branch) RelatedCommits() ([]Commit, error) {
+	commits, err := b.git().CommitRange(b.forkBase(), b.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var related []Commit
+	for _, c := range commits {
+		if mentionsTicket(c.Subject, b.Name) {
+			related = append(related, c)
+		}
+	}
+
+	logging.Instance().Debugf("found %d commits related to %s", len(related), b.Name)
+
+	return related, nil
+}
+
+// forkBase is the default branch the branch was started from, preferring origin's copy since the local one may be stale.
+// commits reachable from it (eg. the squashed releases of earlier features) are not part of the branch
+func (b *Branch) forkBase() string {
+	defaultBranch, err := b.git().DefaultBranch()
+	if err != nil || defaultBranch == b.Name {
+		logging.Instance().Debugf("could not find the default branch %s forked from, reading its whole history. %v", b.Name, err)
+		return ""
+	}
+
+	if b.git().RemoteBranchExists(defaultBranch) {
+		return "origin/" + defaultBranch
+	}
+	if b.git().LocalBranchExists(defaultBranch) {
+		return defaultBranch
+	}
+	return ""
+}
+
+// mentionsTicket reports if subject holds ticket as a whole token, so that 'ABC-1' does not match 'ABC-12' and '1' does not match 'ABC-1'
+func mentionsTicket(subject, ticket string) bool {
+	pattern := `(?:^|[^\w-])` + regexp.QuoteMeta(ticket) + `(?:[^\w-]|$)`
+	return regexp.MustCompile(pattern).MatchString(subject)
 }
 
 func (b *Branch) RelatedLogs() (string, error) {
-	cmd := exec.Command("bash", "-c", fmt.Sprintf("git log --pretty=oneline --first-parent --format='`%%h` - %%s' | grep '%s'", b.Name))
-	stdout, err := cmd.CombinedOutput()
+	commits, err := b.RelatedCommits()
+	if err != nil {
+		return "", err
+	}
 
-	return common.CleanstdoutMultiline(stdout), err
+	var logs []string
+	for _, c := range commits {
+		logs = append(logs, c.String())
+	}
+
+	return strings.Join(logs, "\n"), nil
 }
 
 func (b *Branch) String() string {
 	return b.Name
-}
\ No newline at end of file
+}
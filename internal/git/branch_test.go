@@ -0,0 +1,87 @@
+package git
+
+import (
+	"testing"
+)
+
+func subjects(commits []Commit) []string {
+	var s []string
+	for _, c := range commits {
+		s = append(s, c.Subject)
+	}
+	return s
+}
+
+func TestRelatedCommits(t *testing.T) {
+	m := NewMemoryBackend("/work/app", "main")
+
+	// earlier releases squashed onto the default branch, whose tickets contain the new one
+	commit(t, m, "ABC-1 my first feature")
+	commit(t, m, "ABC-12 another feature")
+	if err := m.Push("main", false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Checkout("1", true); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, m, "1 feat: the thing")
+	commit(t, m, "unrelated tidy up")
+	commit(t, m, "1 fix: the other thing")
+
+	related, err := newBranch(m, "1").RelatedCommits()
+	if err != nil {
+		t.Fatalf("RelatedCommits failed. %v", err)
+	}
+
+	want := []string{"1 fix: the other thing", "1 feat: the thing"}
+	if got := subjects(related); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("RelatedCommits = %v, want %v", got, want)
+	}
+}
+
+func TestRelatedCommitsUnpushedDefault(t *testing.T) {
+	m := NewMemoryBackend("/work/app", "main")
+	commit(t, m, "ABC-1 first release")
+
+	// without origin's copy of the default branch, the local one bounds the range
+	if err := m.DeleteRemoteBranch("main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Checkout("ABC-1", true); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, m, "ABC-1 feat: again")
+
+	related, err := newBranch(m, "ABC-1").RelatedCommits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := subjects(related); len(got) != 1 || got[0] != "ABC-1 feat: again" {
+		t.Errorf("RelatedCommits = %v, want [ABC-1 feat: again]", got)
+	}
+}
+
+func TestMentionsTicket(t *testing.T) {
+	tests := []struct {
+		subject string
+		ticket string
+		want bool
+	}{
+		{"ABC-1 feat: thing", "ABC-1", true},
+		{"fix: thing (ABC-1)", "ABC-1", true},
+		{"ABC-12 feat: thing", "ABC-1", false},
+		{"XABC-1 feat: thing", "ABC-1", false},
+		{"ABC-1 my first feature", "1", false},
+		{"1 feat: thing", "1", true},
+		{"fix #1", "1", true},
+		{"v1 release", "1", false},
+		{"feature/login: done", "feature/login", true},
+	}
+
+	for _, tt := range tests {
+		if got := mentionsTicket(tt.subject, tt.ticket); got != tt.want {
+			t.Errorf("mentionsTicket(%q, %q) = %t, want %t", tt.subject, tt.ticket, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+const (
+	fieldSep = "\x1f"
+	recordSep = "\x1e"
+)
+
+// goodSignature finds the signer in the output of verify-tag, eg. 'Good signature from "Jane <jane@example.com>"' (gpg) or 'Good "git" signature for jane@example.com with ED25519 key SHA256:...' (ssh)
+var goodSignature = regexp.MustCompile(`Good signature from "([^"]+)"|Good "[^"]*" signature for (\S+)`)
+
+type ExecBackend struct {
+	Dir string
+}
+
+func NewExecBackend(dir string) *ExecBackend {
+	return &ExecBackend{Dir: dir}
+}
+
+func (e *ExecBackend) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = e.Dir
+
+	logging.Instance().Debugf("running git command with arguments: %q", args)
+
+	return cmd
+}
+
+func (e *ExecBackend) output(args ...string) (string, error) {
+	stdout, err := e.command(args...).Output()
+	return common.CleanstdoutMultiline(stdout), err
+}
+
+func (e *ExecBackend) run(args ...string) error {
+	stdout, err := e.command(args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stdout))
+	}
+
+	logging.Instance().Debugf("git %s completed with output: %s", args[0], common.CleanstdoutMultiline(stdout))
+
+	return nil
+}
+
+func (e *ExecBackend) IsRepository() bool {
+	_, err := e.output("rev-parse", "--git-dir")
+	return err == nil
+}
+
+func (e *ExecBackend) ProjectRoot() (string, error) {
+	return e.output("rev-parse", "--show-toplevel")
+}
+
+func (e *ExecBackend) GitDir() (string, error) {
+	return e.output("rev-parse", "--absolute-git-dir")
+}
+
+func (e *ExecBackend) CurrentBranch() (string, error) {
+	return e.output("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+func (e *ExecBackend) DefaultBranch() (string, error) {
+	out, err := e.output("remote", "show", "origin")
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "HEAD branch:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "HEAD branch:")), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (e *ExecBackend) LocalBranchExists(name string) bool {
+	_, err := e.output("rev-parse", "--verify", "--quiet", "refs/heads/" + name)
+	return err == nil
+}
+
+func (e *ExecBackend) RemoteBranchExists(name string) bool {
+	_, err := e.output("ls-remote", "--exit-code", "--heads", "origin", "refs/heads/" + name)
+	return err == nil
+}
+
+func (e *ExecBackend) UncommittedChanges() bool {
+	out, err := e.output("status", "--porcelain")
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" && strings.ContainsRune("AMDR", rune(line[0])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *ExecBackend) ConflictedFiles() ([]string, error) {
+	out, err := e.output("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range strings.Split(out, "\n") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+
+	return files, nil
+}
+
+func (e *ExecBackend) Head() (string, error) {
+	return e.output("rev-parse", "HEAD")
+}
+
+func (e *ExecBackend) Commits(max int) ([]Commit, error) {
+	var args []string
+	if max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", max))
+	}
+
+	return e.log(args...)
+}
+
+func (e *ExecBackend) CommitRange(from, to string) ([]Commit, error) {
+	if from == "" {
+		return e.log(to)
+	}
+	return e.log(from + ".." + to)
+}
+
+func (e *ExecBackend) log(args ...string) ([]Commit, error) {
+	args = append([]string{"log", "--first-parent", "--format=%H" + fieldSep + "%h" + fieldSep + "%an" + fieldSep + "%s" + fieldSep + "%b" + recordSep}, args...)
+
+	stdout, err := e.command(args...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(stdout), recordSep) {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), fieldSep)
+		if len(fields) < 5 {
+			continue
+		}
+
+		commits = append(commits, Commit{
+			Hash: fields[0],
+			ShortHash: fields[1],
+			Author: fields[2],
+			Subject: fields[3],
+			Body: strings.TrimSpace(fields[4]),
+		})
+	}
+
+	return commits, nil
+}
+
+func (e *ExecBackend) MergedTags(branch string) ([]string, error) {
+	out, err := e.output("tag", "--merged", branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, t := range strings.Split(out, "\n") {
+		if t != "" {
+			tags = append(tags, strings.TrimSpace(t))
+		}
+	}
+
+	return tags, nil
+}
+
+func (e *ExecBackend) LatestTag() (string, error) {
+	return e.output("describe", "--tags", "--abbrev=0")
+}
+
+func (e *ExecBackend) TagDetails(name string) (Tag, error) {
+	// the subject and body leave out the signature of signed tags
+	out, err := e.output("for-each-ref", "--format=%(creatordate:iso-strict)" + fieldSep + "%(contents:subject)%0a%0a%(contents:body)", "refs/tags/" + name)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	fields := strings.SplitN(out, fieldSep, 2)
+	if len(fields) < 2 {
+		return Tag{}, fmt.Errorf("tag '%s' not found", name)
+	}
+
+	date, err := time.Parse(time.RFC3339, strings.TrimSpace(fields[0]))
+	if err != nil {
+		return Tag{}, fmt.Errorf("failed to read the date of tag %s. %v", name, err)
+	}
+
+	return Tag{Name: name, Message: strings.TrimSpace(fields[1]), Date: date}, nil
+}
+
+func (e *ExecBackend) RevParse(ref string) (string, error) {
+	return e.output("rev-parse", "--verify", "--quiet", ref)
+}
+
+func (e *ExecBackend) UntrackedFiles() ([]string, error) {
+	out, err := e.output("ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range strings.Split(out, "\n") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+
+	return files, nil
+}
+
+func (e *ExecBackend) RebaseInProgress() bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		path, err := e.output("rev-parse", "--git-path", dir)
+		if err != nil {
+			continue
+		}
+		if e.Dir != "" && !strings.HasPrefix(path, "/") {
+			path = e.Dir + "/" + path
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ExecBackend) AheadBehind(branch string) (int, int, error) {
+	out, err := e.output("rev-list", "--left-right", "--count", "refs/heads/" + branch + "...refs/remotes/origin/" + branch)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var ahead, behind int
+	if _, err := fmt.Sscanf(out, "%d %d", &ahead, &behind); err != nil {
+		return 0, 0, fmt.Errorf("unexpected rev-list output '%s'. %v", out, err)
+	}
+
+	return ahead, behind, nil
+}
+
+func (e *ExecBackend) ChangedFiles(base string) ([]string, error) {
+	out, err := e.output("diff", "--name-only", base + "...HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range strings.Split(out, "\n") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+
+	return files, nil
+}
+
+func (e *ExecBackend) RemoteURL() (string, error) {
+	return e.output("remote", "get-url", "origin")
+}
+
+func (e *ExecBackend) Checkout(name string, create bool) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+
+	if create {
+		return e.run("checkout", "-b", name)
+	}
+	return e.run("checkout", name)
+}
+
+func (e *ExecBackend) DeleteLocalBranch(name string) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+	return e.run("branch", "-D", name)
+}
+
+func (e *ExecBackend) DeleteRemoteBranch(name string) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+	return e.run("push", "origin", "--delete", name)
+}
+
+func (e *ExecBackend) Stage(paths ...string) error {
+	return e.run(append([]string{"add", "--"}, paths...)...)
+}
+
+func (e *ExecBackend) StageAll() error {
+	return e.run("add", "-A")
+}
+
+func (e *ExecBackend) Commit(message string) error {
+	return e.run("commit", "-m", message)
+}
+
+func (e *ExecBackend) Fetch() error {
+	return e.run("fetch", "--tags", "--force")
+}
+
+func (e *ExecBackend) Pull() error {
+	return e.run("pull", "--all")
+}
+
+func (e *ExecBackend) Push(branch string, setUpstream bool) error {
+	if !setUpstream {
+		return e.run("push")
+	}
+
+	if err := validRefName(branch); err != nil {
+		return err
+	}
+	return e.run("push", "--set-upstream", "origin", branch)
+}
+
+// ForcePush updates the remote branch after a rebase, refusing to overwrite commits which were not fetched first
+func (e *ExecBackend) ForcePush(branch string) error {
+	if err := validRefName(branch); err != nil {
+		return err
+	}
+	return e.run("push", "--force-with-lease", "--set-upstream", "origin", branch)
+}
+
+func (e *ExecBackend) PushTags() error {
+	return e.run("push", "--tags", "--force")
+}
+
+func (e *ExecBackend) Rebase(onto string) error {
+	if err := validRefName(onto); err != nil {
+		return err
+	}
+	return e.run("rebase", onto)
+}
+
+func (e *ExecBackend) RebaseOnto(onto, upstream string) error {
+	if err := validRefName(onto); err != nil {
+		return err
+	}
+	if err := validRefName(upstream); err != nil {
+		return err
+	}
+	return e.run("rebase", "--onto", onto, upstream)
+}
+
+func (e *ExecBackend) ContinueRebase() error {
+	return e.run("-c", "core.editor=true", "rebase", "--continue")
+}
+
+func (e *ExecBackend) SquashMerge(branch string) error {
+	if err := validRefName(branch); err != nil {
+		return err
+	}
+	return e.run("merge", "--squash", branch)
+}
+
+func (e *ExecBackend) CreateTag(name, message string, force bool, signing Signing) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+	return e.run(signing.tagArgs(name, message, force)...)
+}
+
+func (e *ExecBackend) VerifyTag(name string) (Signature, error) {
+	if err := validRefName(name); err != nil {
+		return Signature{}, err
+	}
+
+	// lightweight tags point straight at a commit and cannot be signed
+	objectType, err := e.output("cat-file", "-t", "refs/tags/" + name)
+	if err != nil {
+		return Signature{}, fmt.Errorf("tag '%s' not found", name)
+	}
+	if objectType != "tag" {
+		return Signature{}, nil
+	}
+
+	content, err := e.output("cat-file", "tag", "refs/tags/" + name)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	signature := Signature{}
+	for marker, format := range signatureMarkers {
+		if strings.Contains(content, marker) {
+			signature.Format = format
+		}
+	}
+	if !signature.Signed() {
+		return signature, nil
+	}
+
+	// git reports the result of gpg and ssh-keygen on stderr
+	out, err := e.command("verify-tag", "refs/tags/" + name).CombinedOutput()
+	signature.Detail = common.CleanstdoutMultiline(out)
+	signature.Valid = err == nil
+	if m := goodSignature.FindStringSubmatch(signature.Detail); m != nil {
+		signature.Signer = m[1] + m[2]
+	}
+
+	logging.Instance().Debugf("verified %s signature of tag %s (valid: %t): %s", signature.Format, name, signature.Valid, signature.Detail)
+
+	return signature, nil
+}
+
+func (e *ExecBackend) DeleteTag(name string) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+	return e.run("tag", "-d", name)
+}
+
+func (e *ExecBackend) ResetHard(ref string) error {
+	return e.run("reset", "--hard", ref)
+}
+
+func (e *ExecBackend) CheckoutAt(name, commit string) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+	return e.run("checkout", "-B", name, commit)
+}
+
+func (e *ExecBackend) UpdateRef(ref, object string) error {
+	if err := validRefName(ref); err != nil {
+		return err
+	}
+	return e.run("update-ref", ref, object)
+}
+
+func (e *ExecBackend) AbortRebase() error {
+	return e.run("rebase", "--abort")
+}
+
+func (e *ExecBackend) StashCreate() (string, error) {
+	return e.output("stash", "create")
+}
+
+func (e *ExecBackend) StashApply(ref string) error {
+	return e.run("stash", "apply", ref)
+}
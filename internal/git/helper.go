@@ -1,63 +1,51 @@
 package git
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
-	"os/exec"
 	"regexp"
+	"sort"
 	"strings"
 
 	"sykesdev.ca/gog/config"
-	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/common/constants"
 	"sykesdev.ca/gog/internal/logging"
 	"sykesdev.ca/gog/internal/semver"
 )
 
-func repositoryIsValid() bool {
-	cmd := exec.Command("git", "status")
-	_, err := cmd.Output()
-	
-	logging.Instance().Debugf("valid repository: %t", err == nil)
+func repositoryIsValid(b Backend) bool {
+	valid := b.IsRepository()
 
-	return err == nil
+	logging.Instance().Debugf("valid repository: %t", valid)
+
+	return valid
 }
 
-func localBranchExists(branch *Branch) bool {
-	cmd := exec.Command("bash", "-c", fmt.Sprintf("git branch | egrep %s", branch.Name))
-	_, err := cmd.Output()
+func localBranchExists(b Backend, branch *Branch) bool {
+	exists := b.LocalBranchExists(branch.Name)
 
-	logging.Instance().Debugf("local branch exists: %t", err == nil)
+	logging.Instance().Debugf("local branch exists: %t", exists)
 
-	return err == nil
+	return exists
 }
 
-func remoteBranchExists(branch *Branch) bool {
-	cmd := exec.Command("bash", "-c", "git ls-remote --head origin | egrep " + branch.Name)
-	_, err := cmd.Output()
+func remoteBranchExists(b Backend, branch *Branch) bool {
+	exists := b.RemoteBranchExists(branch.Name)
 
-	logging.Instance().Debugf("remote branch exists: %t", err == nil)
+	logging.Instance().Debugf("remote branch exists: %t", exists)
 
-	return err == nil
+	return exists
 }
 
-func getCurrentBranch() (string, error) {
-	cmd := exec.Command("bash", "-c", "git branch | grep '*' | cut -d' ' -f2")
-	stdout, err := cmd.CombinedOutput()
-
-	return common.CleanStdoutSingleline(stdout), err
+func getCurrentBranch(b Backend) (string, error) {
+	return b.CurrentBranch()
 }
 
-func originDefaultBranch() (string, error) {
-	defaultBranchCmd := exec.Command("bash", "-c", "git remote show origin | sed -n '/HEAD branch/s/.*: //p'")
-	defaultBranch, err := defaultBranchCmd.CombinedOutput()
-
-	return common.CleanStdoutSingleline(defaultBranch), err
+func originDefaultBranch(b Backend) (string, error) {
+	return b.DefaultBranch()
 }
 
-func projectExistingVersionPrefix() (string, error) {
-	tagName, err := originLatestTagName()
+func projectExistingVersionPrefix(b Backend) (string, error) {
+	tagName, err := originLatestTagName(b)
 	if err != nil {
 		logging.Instance().Debugf("error ocurred when reading latest tagName from repo: %v\n%s", err, tagName)
 
@@ -71,6 +59,16 @@ func projectExistingVersionPrefix() (string, error) {
 
 	logging.Instance().Debugf("origin current/latest tagName: %s", tagName)
 
+	if component, ok := config.AppConfig().ActiveComponent(); ok {
+		logging.Instance().Debugf("using the tag prefix of component %s", component.Name)
+		return component.TagPrefix(), nil
+	}
+
+	if component, ok := config.AppConfig().ComponentForTag(tagName); ok {
+		logging.Instance().Debugf("latest tag %s belongs to component %s, using the configured prefix for the repository", tagName, component.Name)
+		return config.AppConfig().TagPrefix(), nil
+	}
+
 	var existingPrefix string
 	if prefixSearch := regexp.MustCompile(constants.VersionPrefixRegexp).FindStringSubmatch(tagName); len(prefixSearch) > 0 {
 		existingPrefix = strings.TrimSpace(prefixSearch[0])
@@ -83,65 +81,101 @@ func projectExistingVersionPrefix() (string, error) {
 	return existingPrefix, nil
 }
 
-func originLatestFullVersion() (semver.Semver, error) {
-	version := semver.Semver{0,0,0}
+// VersionTag is a release tag along with the version it holds
+type VersionTag struct {
+	Name string `json:"name"`
+	Version semver.Semver `json:"version"`
+}
+
+func originLatestFullVersion(b Backend) (semver.Semver, error) {
+	tags, err := originVersionTags(b)
+	if err != nil || len(tags) == 0 {
+		return semver.Semver{}, err
+	}
+
+	latestTag := tags[len(tags) - 1].Version
+
+	logging.Instance().Debugf("latest tag found: %s", latestTag)
 
-	defaultBranch, err := originDefaultBranch()
+	return latestTag, nil
+}
+
+// originVersionTags lists the semver tags merged into the default branch, oldest version first. with an active component only its tags are listed,
+// otherwise the tags of components are left out. each version is listed once
+func originVersionTags(b Backend) ([]VersionTag, error) {
+	defaultBranch, err := originDefaultBranch(b)
 	if err != nil {
-		return version, err
+		return nil, err
 	}
 
 	logging.Instance().Debugf("default branch at: %s", defaultBranch)
 
-	tagCmd := exec.Command("bash", "-c", fmt.Sprintf("git tag --merged %s", defaultBranch))
-	tagOut, err := tagCmd.CombinedOutput()
+	tags, err := b.MergedTags(defaultBranch)
 	if err != nil {
 		
 		logging.Instance().Debugf("error ocurred when capturing current tag version from remote (%s): %v", defaultBranch, err)
 
 		if strings.Contains(err.Error(), "128") {
 			logging.Instance().Debug("defaulting to verion 0.0.0 since no existing tags found on remote")
-			return version, nil
+			return nil, nil
 		}
 		
-		return version, err
+		return nil, err
 	}
 
 	semverRegex, err := regexp.Compile(constants.FullSemverRegexp)
 	if err != nil {
-		return version, err
+		return nil, err
 	}
 
-	logging.Instance().Debug("checking for latest existing tag from remote")
+	logging.Instance().Debug("checking for existing version tags from remote")
 
-	latestTag := semver.Semver{0,0,0}
-	tagScanner := bufio.NewScanner(bytes.NewReader(tagOut))
-	for tagScanner.Scan() {
-		tag := tagScanner.Text()
+	component, isComponent := config.AppConfig().ActiveComponent()
 
-		logging.Instance().Debugf("processing: %s", tag)
+	var versions []VersionTag
+	seen := map[semver.Semver]bool{}
+	for _, name := range tags {
+		logging.Instance().Debugf("processing: %s", name)
+
+		tag := name
+		if isComponent {
+			if !strings.HasPrefix(tag, component.TagPrefix()) {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, component.TagPrefix())
+		} else if _, ok := config.AppConfig().ComponentForTag(tag); ok {
+			continue
+		}
 
 		if matched := semverRegex.MatchString(tag); matched {
 			semverTag, err := semver.Parse(tag)
 			if err != nil {
-				return version, err
+				return nil, err
 			}
 
-			if semverTag.GreaterThan(latestTag) {
-				logging.Instance().Debugf("found newer tag version: %s", semverTag)
-				latestTag = semverTag
+			if !seen[semverTag] {
+				seen[semverTag] = true
+				versions = append(versions, VersionTag{Name: name, Version: semverTag})
 			}
 		}
 	}
 
-	logging.Instance().Debugf("latest tag found: %s", latestTag)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[j].Version.GreaterThan(versions[i].Version)
+	})
 
-	return latestTag, nil
+	return versions, nil
 }
 
-func originLatestTagName() (string, error) {
-	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0")
-	stdout, err := cmd.CombinedOutput()
+func originLatestTagName(b Backend) (string, error) {
+	return b.LatestTag()
+}
 
-	return common.CleanstdoutMultiline(stdout), err
-}
\ No newline at end of file
+func onlyGOGMetadata(files []string) bool {
+	for _, f := range files {
+		if !strings.HasPrefix(f, ".gog/") {
+			return false
+		}
+	}
+	return true
+}
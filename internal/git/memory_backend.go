@@ -0,0 +1,692 @@
+package git
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryTag struct {
+	target string
+	message string
+	date time.Time
+	signing Signing
+}
+
+// MemoryBackend is an in-process git backend which models branches, tags and a single origin remote in memory
+type MemoryBackend struct {
+	mu sync.Mutex
+
+	root string
+	defaultBranch string
+	current string
+	author string
+	remoteURL string
+
+	commits map[string]Commit
+	branches map[string][]string
+	remoteBranches map[string][]string
+	tags map[string]memoryTag
+	remoteTags map[string]memoryTag
+
+	dirty bool
+	staged bool
+	squashed []string
+	counter int
+}
+
+func NewMemoryBackend(root, defaultBranch string) *MemoryBackend {
+	m := &MemoryBackend{
+		root: root,
+		defaultBranch: defaultBranch,
+		current: defaultBranch,
+		author: "GOG",
+		commits: make(map[string]Commit),
+		branches: make(map[string][]string),
+		remoteBranches: make(map[string][]string),
+		tags: make(map[string]memoryTag),
+		remoteTags: make(map[string]memoryTag),
+	}
+
+	initial := m.newCommit("initial commit")
+	m.branches[defaultBranch] = []string{initial}
+	m.remoteBranches[defaultBranch] = []string{initial}
+
+	return m
+}
+
+func (m *MemoryBackend) SetRemoteURL(url string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remoteURL = url
+}
+
+func (m *MemoryBackend) RemoteURL() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.remoteURL == "" {
+		return "", fmt.Errorf("no such remote 'origin'")
+	}
+	return m.remoteURL, nil
+}
+
+// Touch marks the working tree as modified, as if a file had been edited
+func (m *MemoryBackend) Touch() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dirty = true
+}
+
+func (m *MemoryBackend) newCommit(message string) string {
+	m.counter++
+	hash := fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%d:%s", m.counter, message))))
+
+	subject, body := message, ""
+	if i := strings.Index(message, "\n"); i >= 0 {
+		subject, body = message[:i], strings.TrimSpace(message[i:])
+	}
+
+	m.commits[hash] = Commit{Hash: hash, ShortHash: hash[:7], Author: m.author, Subject: subject, Body: body}
+
+	return hash
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryBackend) IsRepository() bool {
+	return true
+}
+
+func (m *MemoryBackend) ProjectRoot() (string, error) {
+	return m.root, nil
+}
+
+func (m *MemoryBackend) GitDir() (string, error) {
+	return m.root + "/.git", nil
+}
+
+func (m *MemoryBackend) CurrentBranch() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.current, nil
+}
+
+func (m *MemoryBackend) DefaultBranch() (string, error) {
+	return m.defaultBranch, nil
+}
+
+func (m *MemoryBackend) LocalBranchExists(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.branches[name]
+	return ok
+}
+
+func (m *MemoryBackend) RemoteBranchExists(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.remoteBranches[name]
+	return ok
+}
+
+func (m *MemoryBackend) UncommittedChanges() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.staged
+}
+
+func (m *MemoryBackend) ConflictedFiles() ([]string, error) {
+	return nil, nil
+}
+
+func (m *MemoryBackend) Head() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := m.branches[m.current]
+	if len(history) == 0 {
+		return "", errors.New("current branch does not have any commits")
+	}
+
+	return history[len(history) - 1], nil
+}
+
+func (m *MemoryBackend) Commits(max int) ([]Commit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := m.branches[m.current]
+
+	var commits []Commit
+	for i := len(history) - 1; i >= 0; i-- {
+		if max > 0 && len(commits) == max {
+			break
+		}
+		commits = append(commits, m.commits[history[i]])
+	}
+
+	return commits, nil
+}
+
+func (m *MemoryBackend) CommitRange(from, to string) ([]Commit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history, err := m.resolve(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var exclude []string
+	if from != "" {
+		if exclude, err = m.resolve(from); err != nil {
+			return nil, err
+		}
+	}
+
+	var commits []Commit
+	for i := len(history) - 1; i >= 0; i-- {
+		if !contains(exclude, history[i]) {
+			commits = append(commits, m.commits[history[i]])
+		}
+	}
+
+	return commits, nil
+}
+
+// resolve returns the history of a local branch, an 'origin/' branch, a tag or a commit, oldest commit first
+func (m *MemoryBackend) resolve(ref string) ([]string, error) {
+	if history, ok := m.branches[ref]; ok {
+		return history, nil
+	}
+	if history, ok := m.remoteBranches[strings.TrimPrefix(ref, "origin/")]; ok && strings.HasPrefix(ref, "origin/") {
+		return history, nil
+	}
+	if t, ok := m.tags[ref]; ok {
+		ref = t.target
+	}
+	return m.history(ref)
+}
+
+func (m *MemoryBackend) MergedTags(branch string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history, ok := m.branches[branch]
+	if !ok {
+		history, ok = m.remoteBranches[strings.TrimPrefix(branch, "origin/")]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown branch: %s", branch)
+	}
+
+	var tags []string
+	for name, t := range m.tags {
+		if contains(history, t.target) {
+			tags = append(tags, name)
+		}
+	}
+	sort.Strings(tags)
+
+	return tags, nil
+}
+
+func (m *MemoryBackend) LatestTag() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := m.branches[m.current]
+	for i := len(history) - 1; i >= 0; i-- {
+		var names []string
+		for name, t := range m.tags {
+			if t.target == history[i] {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			return names[len(names) - 1], nil
+		}
+	}
+
+	return "", errors.New("exit status 128. fatal: No names found, cannot describe anything")
+}
+
+func (m *MemoryBackend) TagDetails(name string) (Tag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tags[name]
+	if !ok {
+		return Tag{}, fmt.Errorf("tag '%s' not found", name)
+	}
+
+	return Tag{Name: name, Message: t.message, Date: t.date}, nil
+}
+
+func (m *MemoryBackend) RevParse(ref string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if history, ok := m.branches[strings.TrimPrefix(ref, "refs/heads/")]; ok && len(history) > 0 {
+		return history[len(history) - 1], nil
+	}
+	if t, ok := m.tags[strings.TrimPrefix(ref, "refs/tags/")]; ok {
+		return t.target, nil
+	}
+	if _, ok := m.commits[ref]; ok {
+		return ref, nil
+	}
+
+	return "", fmt.Errorf("unknown revision: %s", ref)
+}
+
+func (m *MemoryBackend) AheadBehind(branch string) (int, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	local, ok := m.branches[branch]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown branch: %s", branch)
+	}
+	remote, ok := m.remoteBranches[branch]
+	if !ok {
+		return 0, 0, fmt.Errorf("branch %s has not been pushed to origin", branch)
+	}
+
+	var ahead, behind int
+	for _, c := range local {
+		if !contains(remote, c) {
+			ahead++
+		}
+	}
+	for _, c := range remote {
+		if !contains(local, c) {
+			behind++
+		}
+	}
+
+	return ahead, behind, nil
+}
+
+// ChangedFiles is always empty since the memory backend does not model file contents
+func (m *MemoryBackend) ChangedFiles(base string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *MemoryBackend) UntrackedFiles() ([]string, error) {
+	return nil, nil
+}
+
+func (m *MemoryBackend) RebaseInProgress() bool {
+	return false
+}
+
+func (m *MemoryBackend) Checkout(name string, create bool) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, exists := m.branches[name]
+	switch {
+	case create && exists:
+		return fmt.Errorf("a branch named '%s' already exists", name)
+	case create:
+		m.branches[name] = append([]string{}, m.branches[m.current]...)
+	case !exists:
+		remote, ok := m.remoteBranches[name]
+		if !ok {
+			return fmt.Errorf("pathspec '%s' did not match any branch known to git", name)
+		}
+		m.branches[name] = append([]string{}, remote...)
+	}
+
+	m.current = name
+
+	return nil
+}
+
+func (m *MemoryBackend) DeleteLocalBranch(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if name == m.current {
+		return fmt.Errorf("cannot delete branch '%s' which is currently checked out", name)
+	}
+	if _, ok := m.branches[name]; !ok {
+		return fmt.Errorf("branch '%s' not found", name)
+	}
+	delete(m.branches, name)
+
+	return nil
+}
+
+func (m *MemoryBackend) DeleteRemoteBranch(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.remoteBranches[name]; !ok {
+		return fmt.Errorf("unable to delete '%s': remote ref does not exist", name)
+	}
+	delete(m.remoteBranches, name)
+
+	return nil
+}
+
+func (m *MemoryBackend) Stage(paths ...string) error {
+	return m.StageAll()
+}
+
+func (m *MemoryBackend) StageAll() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.dirty {
+		m.staged, m.dirty = true, false
+	}
+
+	return nil
+}
+
+func (m *MemoryBackend) Commit(message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.staged {
+		return errors.New("nothing to commit, working tree clean")
+	}
+
+	m.branches[m.current] = append(m.branches[m.current], m.newCommit(message))
+	m.staged, m.squashed = false, nil
+
+	return nil
+}
+
+func (m *MemoryBackend) Fetch() error {
+	return nil
+}
+
+func (m *MemoryBackend) Pull() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	remote, ok := m.remoteBranches[m.current]
+	if !ok {
+		return nil
+	}
+
+	local := m.branches[m.current]
+	for _, c := range remote {
+		if !contains(local, c) {
+			local = append(local, c)
+		}
+	}
+	m.branches[m.current] = local
+
+	return nil
+}
+
+func (m *MemoryBackend) Push(branch string, setUpstream bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.remoteBranches[m.current]; !ok && !setUpstream {
+		return fmt.Errorf("the current branch %s has no upstream branch", m.current)
+	}
+
+	m.remoteBranches[m.current] = append([]string{}, m.branches[m.current]...)
+
+	return nil
+}
+
+func (m *MemoryBackend) ForcePush(branch string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remoteBranches[branch] = append([]string{}, m.branches[branch]...)
+
+	return nil
+}
+
+func (m *MemoryBackend) PushTags() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, t := range m.tags {
+		m.remoteTags[name] = t
+	}
+
+	return nil
+}
+
+func (m *MemoryBackend) Rebase(onto string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	base, ok := m.branches[onto]
+	if !ok {
+		return fmt.Errorf("invalid upstream '%s'", onto)
+	}
+
+	rebased := append([]string{}, base...)
+	for _, c := range m.branches[m.current] {
+		if !contains(base, c) {
+			rebased = append(rebased, c)
+		}
+	}
+	m.branches[m.current] = rebased
+
+	return nil
+}
+
+func (m *MemoryBackend) RebaseOnto(onto, upstream string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	base, ok := m.branches[onto]
+	if !ok {
+		base, ok = m.remoteBranches[strings.TrimPrefix(onto, "origin/")]
+	}
+	if !ok {
+		return fmt.Errorf("invalid upstream '%s'", onto)
+	}
+
+	history := m.branches[m.current]
+	rebased := append([]string{}, base...)
+	for i, c := range history {
+		if c == upstream {
+			rebased = append(rebased, history[i+1:]...)
+			m.branches[m.current] = rebased
+			return nil
+		}
+	}
+
+	return fmt.Errorf("upstream commit %s is not part of %s", upstream, m.current)
+}
+
+func (m *MemoryBackend) ContinueRebase() error {
+	return errors.New("no rebase in progress")
+}
+
+func (m *MemoryBackend) SquashMerge(branch string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source, ok := m.branches[branch]
+	if !ok {
+		return fmt.Errorf("%s - not something we can merge", branch)
+	}
+
+	current := m.branches[m.current]
+	for _, c := range source {
+		if !contains(current, c) {
+			m.squashed = append(m.squashed, c)
+		}
+	}
+	if len(m.squashed) > 0 {
+		m.staged = true
+	}
+
+	return nil
+}
+
+func (m *MemoryBackend) CreateTag(name, message string, force bool, signing Signing) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.tags[name]; exists && !force {
+		return fmt.Errorf("tag '%s' already exists", name)
+	}
+
+	history := m.branches[m.current]
+	if len(history) == 0 {
+		return errors.New("failed to resolve 'HEAD' as a valid ref")
+	}
+	m.tags[name] = memoryTag{target: history[len(history) - 1], message: message, date: time.Now(), signing: signing}
+
+	return nil
+}
+
+// VerifyTag treats every signed tag as validly signed by its key, since there is nothing to check the signature against
+func (m *MemoryBackend) VerifyTag(name string) (Signature, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tags[name]
+	if !ok {
+		return Signature{}, fmt.Errorf("tag '%s' not found", name)
+	}
+	if !t.signing.Sign {
+		return Signature{}, nil
+	}
+
+	format := t.signing.Format
+	if format == "" {
+		format = "gpg"
+	}
+	return Signature{Format: format, Valid: true, Signer: t.signing.Key}, nil
+}
+
+func (m *MemoryBackend) DeleteTag(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tags[name]; !ok {
+		return fmt.Errorf("tag '%s' not found", name)
+	}
+	delete(m.tags, name)
+
+	return nil
+}
+
+func (m *MemoryBackend) history(commit string) ([]string, error) {
+	for _, history := range m.branches {
+		for i, c := range history {
+			if c == commit {
+				return append([]string{}, history[:i+1]...), nil
+			}
+		}
+	}
+	for _, history := range m.remoteBranches {
+		for i, c := range history {
+			if c == commit {
+				return append([]string{}, history[:i+1]...), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unknown revision: %s", commit)
+}
+
+func (m *MemoryBackend) ResetHard(ref string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dirty, m.staged, m.squashed = false, false, nil
+	if ref == "HEAD" {
+		return nil
+	}
+
+	history, err := m.history(ref)
+	if err != nil {
+		return err
+	}
+	m.branches[m.current] = history
+
+	return nil
+}
+
+func (m *MemoryBackend) CheckoutAt(name, commit string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history, err := m.history(commit)
+	if err != nil {
+		return err
+	}
+	m.branches[name] = history
+	m.current = name
+
+	return nil
+}
+
+func (m *MemoryBackend) UpdateRef(ref, object string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if strings.HasPrefix(ref, "refs/tags/") {
+		name := strings.TrimPrefix(ref, "refs/tags/")
+		m.tags[name] = memoryTag{target: object, message: m.tags[name].message}
+		return nil
+	}
+
+	name := strings.TrimPrefix(ref, "refs/heads/")
+	if name == m.current {
+		return fmt.Errorf("cannot force update the current branch '%s'", name)
+	}
+
+	history, err := m.history(object)
+	if err != nil {
+		return err
+	}
+	m.branches[name] = history
+
+	return nil
+}
+
+func (m *MemoryBackend) AbortRebase() error {
+	return errors.New("no rebase in progress")
+}
+
+func (m *MemoryBackend) StashCreate() (string, error) {
+	return "", nil
+}
+
+func (m *MemoryBackend) StashApply(ref string) error {
+	return nil
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"strings"
+	"sync"
+
+	"sykesdev.ca/gog/internal/logging"
+)
+
+// RecordingBackend passes read-only operations through to the wrapped backend and records mutating operations instead of running them
+type RecordingBackend struct {
+	Backend
+
+	mu sync.Mutex
+	operations []string
+	current string
+	staged bool
+}
+
+func NewRecordingBackend(b Backend) *RecordingBackend {
+	return &RecordingBackend{Backend: b}
+}
+
+func (r *RecordingBackend) record(args ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n\"'") {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		quoted = append(quoted, a)
+	}
+
+	operation := "git " + strings.Join(quoted, " ")
+	r.operations = append(r.operations, operation)
+
+	logging.Instance().Debugf("recorded operation: %s", operation)
+
+	return nil
+}
+
+func (r *RecordingBackend) Operations() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string{}, r.operations...)
+}
+
+func (r *RecordingBackend) CurrentBranch() (string, error) {
+	r.mu.Lock()
+	current := r.current
+	r.mu.Unlock()
+
+	if current != "" {
+		return current, nil
+	}
+	return r.Backend.CurrentBranch()
+}
+
+func (r *RecordingBackend) UncommittedChanges() bool {
+	r.mu.Lock()
+	staged := r.staged
+	r.mu.Unlock()
+
+	return staged || r.Backend.UncommittedChanges()
+}
+
+func (r *RecordingBackend) Checkout(name string, create bool) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.current = name
+	r.mu.Unlock()
+
+	if create {
+		return r.record("checkout", "-b", name)
+	}
+	return r.record("checkout", name)
+}
+
+func (r *RecordingBackend) DeleteLocalBranch(name string) error {
+	return r.record("branch", "-D", name)
+}
+
+func (r *RecordingBackend) DeleteRemoteBranch(name string) error {
+	return r.record("push", "origin", "--delete", name)
+}
+
+func (r *RecordingBackend) Stage(paths ...string) error {
+	return r.record(append([]string{"add", "--"}, paths...)...)
+}
+
+func (r *RecordingBackend) StageAll() error {
+	r.mu.Lock()
+	r.staged = true
+	r.mu.Unlock()
+
+	return r.record("add", "-A")
+}
+
+func (r *RecordingBackend) Commit(message string) error {
+	r.mu.Lock()
+	r.staged = false
+	r.mu.Unlock()
+
+	return r.record("commit", "-m", message)
+}
+
+func (r *RecordingBackend) Fetch() error {
+	return r.record("fetch", "--tags", "--force")
+}
+
+func (r *RecordingBackend) Pull() error {
+	return r.record("pull", "--all")
+}
+
+func (r *RecordingBackend) Push(branch string, setUpstream bool) error {
+	if setUpstream {
+		return r.record("push", "--set-upstream", "origin", branch)
+	}
+	return r.record("push")
+}
+
+func (r *RecordingBackend) ForcePush(branch string) error {
+	return r.record("push", "--force-with-lease", "--set-upstream", "origin", branch)
+}
+
+func (r *RecordingBackend) PushTags() error {
+	return r.record("push", "--tags", "--force")
+}
+
+func (r *RecordingBackend) Rebase(onto string) error {
+	return r.record("rebase", onto)
+}
+
+func (r *RecordingBackend) RebaseOnto(onto, upstream string) error {
+	return r.record("rebase", "--onto", onto, upstream)
+}
+
+func (r *RecordingBackend) ContinueRebase() error {
+	return r.record("rebase", "--continue")
+}
+
+func (r *RecordingBackend) SquashMerge(branch string) error {
+	return r.record("merge", "--squash", branch)
+}
+
+func (r *RecordingBackend) CreateTag(name, message string, force bool, signing Signing) error {
+	if err := validRefName(name); err != nil {
+		return err
+	}
+	return r.record(signing.tagArgs(name, message, force)...)
+}
+
+func (r *RecordingBackend) DeleteTag(name string) error {
+	return r.record("tag", "-d", name)
+}
+
+func (r *RecordingBackend) ResetHard(ref string) error {
+	return r.record("reset", "--hard", ref)
+}
+
+func (r *RecordingBackend) CheckoutAt(name, commit string) error {
+	r.mu.Lock()
+	r.current = name
+	r.mu.Unlock()
+
+	return r.record("checkout", "-B", name, commit)
+}
+
+func (r *RecordingBackend) UpdateRef(ref, object string) error {
+	return r.record("update-ref", ref, object)
+}
+
+func (r *RecordingBackend) AbortRebase() error {
+	return r.record("rebase", "--abort")
+}
+
+func (r *RecordingBackend) StashApply(ref string) error {
+	return r.record("stash", "apply", ref)
+}
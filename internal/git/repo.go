@@ -3,12 +3,11 @@ package git
 import (
 	"errors"
 	"fmt"
-	"os/exec"
 	"strings"
 	"sync"
 
-	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
 	"sykesdev.ca/gog/internal/semver"
 )
 
@@ -21,11 +20,17 @@ type Repository struct {
 	CurrentBranch *Branch
 
 	LastTag semver.Semver
+
+	backend Backend
 }
 
 func NewRepository() (*Repository, error) {
+	return NewRepositoryWithBackend(DefaultBackend())
+}
+
+func NewRepositoryWithBackend(backend Backend) (*Repository, error) {
 	var wg sync.WaitGroup
-	r := &Repository{FeatureBranch: &Branch{}}
+	r := &Repository{FeatureBranch: &Branch{backend: backend}, backend: backend}
 
 	rootChan := make(chan []string)
 	prefixChan, dBranchChan, cBranchChan := make(chan string), make(chan string), make(chan string)
@@ -33,13 +38,13 @@ func NewRepository() (*Repository, error) {
 	doneChan := make(chan struct{})
 	errChan := make(chan error)
 
-	if !repositoryIsValid() {
-		return nil, errors.New("directory does not contain a valid git repository")
+	if !repositoryIsValid(backend) {
+		return nil, output.Errorf(output.CodeNotRepository, "directory does not contain a valid git repository")
 	}
 
 	wg.Add(1)
 	go func () {
-		root, err := common.GitProjectRoot()
+		root, err := backend.ProjectRoot()
 		if err != nil {
 			errChan <- err
 		}
@@ -52,7 +57,7 @@ func NewRepository() (*Repository, error) {
 	
 	wg.Add(1)
 	go func ()  {
-		existingPrefix, err := projectExistingVersionPrefix()
+		existingPrefix, err := projectExistingVersionPrefix(backend)
 		if err != nil {
 			errChan <- err
 		}
@@ -66,7 +71,7 @@ func NewRepository() (*Repository, error) {
 
 	wg.Add(1)
 	go func ()  {
-		defaultBranch, err := originDefaultBranch()
+		defaultBranch, err := originDefaultBranch(backend)
 		if err != nil {
 			errChan <- err
 		}
@@ -80,7 +85,7 @@ func NewRepository() (*Repository, error) {
 
 	wg.Add(1)
 	go func() {
-		currentBranch, err := getCurrentBranch()
+		currentBranch, err := getCurrentBranch(backend)
 		if err != nil {
 			errChan <- err
 		}
@@ -94,7 +99,7 @@ func NewRepository() (*Repository, error) {
 
 	wg.Add(1)
 	go func() {
-		latestTag, err := originLatestFullVersion()
+		latestTag, err := originLatestFullVersion(backend)
 		if err != nil {
 			errChan <- err
 		}
@@ -118,9 +123,9 @@ func NewRepository() (*Repository, error) {
 			case existingPrefix := <- prefixChan:
 				r.VersionPrefix = existingPrefix
 			case defaultBranch := <- dBranchChan:
-				r.DefaultBranch = NewBranch(defaultBranch)
+				r.DefaultBranch = r.NewBranch(defaultBranch)
 			case currentBranch := <- cBranchChan:
-				r.CurrentBranch = NewBranch(currentBranch)
+				r.CurrentBranch = r.NewBranch(currentBranch)
 			case tagName := <- lastTagChan:
 				r.LastTag = tagName
 			case e := <- errChan:
@@ -138,47 +143,66 @@ func NewRepository() (*Repository, error) {
 	}
 }
 
+func (r *Repository) Backend() Backend {
+	return r.backend
+}
+
+func (r *Repository) NewBranch(name string) *Branch {
+	return newBranch(r.backend, name)
+}
+
+func (r *Repository) RefreshCurrentBranch() error {
+	currentBranch, err := getCurrentBranch(r.backend)
+	if err != nil {
+		return err
+	}
+
+	r.CurrentBranch = r.NewBranch(currentBranch)
+
+	return nil
+}
+
 func (r *Repository) ContainsBranch(branch string) bool {
-	b := NewBranch(branch)
+	b := r.NewBranch(branch)
 	return b.RemoteExists || b.LocalExists
 }
 
 func (r *Repository) CheckoutBranch(branch *Branch, create, isFeature bool) error {
-	checkoutArgs := make([]string, 0)
-	if create {
-		checkoutArgs = append(checkoutArgs, "-b")
-	}
-	checkoutArgs = append(checkoutArgs, branch.Name)
-
 	logging.Instance().Debugf("checking out branch, %s, with create: %t", branch, create)
 
-	cmd := exec.Command("git", append([]string{"checkout"}, checkoutArgs...)...)
-	stdout, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stdout))
+	if err := r.backend.Checkout(branch.Name, create); err != nil {
+		return err
 	}
 
-	r.CurrentBranch = NewBranch(branch.Name)
+	r.CurrentBranch = r.NewBranch(branch.Name)
 	if create && isFeature {
-		r.FeatureBranch = NewBranch(branch.Name)
+		r.FeatureBranch = r.NewBranch(branch.Name)
 	}
 
 	return nil
 }
 
 func (r *Repository) DeleteBranch(branch *Branch) error {
-	cmdLocal := exec.Command("git", "branch", "-D", branch.Name)
-	localStdout, err := cmdLocal.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(localStdout))
+	if err := r.DeleteLocalBranch(branch); err != nil {
+		return err
+	}
+
+	return r.DeleteRemoteBranch(branch)
+}
+
+func (r *Repository) DeleteLocalBranch(branch *Branch) error {
+	if err := r.backend.DeleteLocalBranch(branch.Name); err != nil {
+		return err
 	}
 
 	logging.Instance().Debugf("deleted local branch: %s", branch.Name)
 
-	cmdRemote := exec.Command("git", "push", "origin", "--delete", branch.Name)
-	remoteStdout, err := cmdRemote.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(remoteStdout))
+	return nil
+}
+
+func (r *Repository) DeleteRemoteBranch(branch *Branch) error {
+	if err := r.backend.DeleteRemoteBranch(branch.Name); err != nil {
+		return err
 	}
 
 	logging.Instance().Debugf("deleted remote branch: %s", branch.Name)
@@ -187,10 +211,8 @@ func (r *Repository) DeleteBranch(branch *Branch) error {
 }
 
 func (r *Repository) StageChanges() error {
-	cmd := exec.Command("git", "add", "-A")
-	stderr, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stderr))
+	if err := r.backend.StageAll(); err != nil {
+		return err
 	}
 
 	logging.Instance().Debug("successfully staged all changes for git repo")
@@ -201,97 +223,157 @@ func (r *Repository) StageChanges() error {
 func (r *Repository) CommitChanges(message string) error {
 	if r.CurrentBranch.UncommittedChanges() {
 		logging.Instance().Debugf("uncommitted changes found... committing them with message: %s", message)
-		cmd := exec.Command("git", "commit", "-m", message)
-		stderr, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stderr))
-		}
+		return r.backend.Commit(message)
 	}
 
 	return nil
 }
 
 func (r *Repository) PullChanges() error {
-	cmd := exec.Command("git", "fetch", "--tags", "--force")
-	stdout, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stdout))
+	if err := r.backend.Fetch(); err != nil {
+		return err
 	}
 
-	logging.Instance().Debugf("fetched tags from remote with output: %s", string(stdout))
+	logging.Instance().Debug("fetched tags from remote")
 
-	cmd = exec.Command("git", "pull", "--all")
-	stdout, err = cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stdout))
+	if err := r.backend.Pull(); err != nil {
+		return err
 	}
 	
-	logging.Instance().Debugf("pulled changes from remote with output: %s", string(stdout))
+	logging.Instance().Debug("pulled changes from remote")
 
 	return nil
 }
 
 func (r *Repository) Push() error {
-	var pushArgs string
-	if !r.CurrentBranch.RemoteExists {
-		pushArgs = "--set-upstream origin " + r.CurrentBranch.Name
-	}
+	logging.Instance().Debugf("pushing changes for %s with upstream: %t", r.CurrentBranch.Name, !r.CurrentBranch.RemoteExists)
 
-	logging.Instance().Debugf("pushing changes for %s with the following arguments: %s", r.CurrentBranch.Name, pushArgs)
+	return r.backend.Push(r.CurrentBranch.Name, !r.CurrentBranch.RemoteExists)
+}
 
-	pushCommand := fmt.Sprintf("git push %s", pushArgs)
-	cmd := exec.Command("bash", "-c", pushCommand)
-	stderr, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stderr))
-	}
+func (r *Repository) ForcePush() error {
+	logging.Instance().Debugf("force pushing rebased changes for %s", r.CurrentBranch.Name)
 
-	return nil
+	return r.backend.ForcePush(r.CurrentBranch.Name)
+}
+
+// ChangedFiles lists the paths changed on the current branch since it forked from the default branch on origin
+func (r *Repository) ChangedFiles() ([]string, error) {
+	return r.backend.ChangedFiles("refs/remotes/origin/" + r.DefaultBranch.Name)
+}
+
+// LatestVersion reads the latest released version again, eg. after selecting another component
+func (r *Repository) LatestVersion() (semver.Semver, error) {
+	return originLatestFullVersion(r.backend)
+}
+
+// VersionTags lists the release tags of the repository (or the active component), oldest version first
+func (r *Repository) VersionTags() ([]VersionTag, error) {
+	return originVersionTags(r.backend)
+}
+
+func (r *Repository) CommitRange(from, to string) ([]Commit, error) {
+	return r.backend.CommitRange(from, to)
+}
+
+func (r *Repository) TagDetails(name string) (Tag, error) {
+	return r.backend.TagDetails(name)
+}
+
+func (r *Repository) RemoteURL() (string, error) {
+	return r.backend.RemoteURL()
 }
 
 func (r *Repository) PushTags() error {
-	cmd := exec.Command("git", "push", "--tags", "--force")
-	stderr, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stderr))
-	}
-	
-	return nil
+	return r.backend.PushTags()
 }
 
 func (r *Repository) Rebase() error {
-	cmd := exec.Command("git", "rebase", r.DefaultBranch.Name)
-	stdout, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stdout))
+	return r.backend.Rebase(r.DefaultBranch.Name)
+}
+
+// RebaseRemote rebases the current branch onto the remote default branch (or the local one if it was never pushed)
+func (r *Repository) RebaseRemote() error {
+	onto := r.DefaultBranch.Name
+	if r.DefaultBranch.RemoteExists {
+		onto = "origin/" + r.DefaultBranch.Name
 	}
 
-	return nil
+	logging.Instance().Debugf("rebasing %s onto %s", r.CurrentBranch, onto)
+
+	return r.resolveRebase(r.backend.Rebase(onto))
 }
 
-func (r *Repository) SquashMerge() error {
-	cmd := exec.Command("git", "merge", "--squash", r.FeatureBranch.Name)
-	stdout, err := cmd.CombinedOutput()
+func (r *Repository) RebaseOnto(upstream string) error {
+	onto := r.DefaultBranch.Name
+	if r.DefaultBranch.RemoteExists {
+		onto = "origin/" + r.DefaultBranch.Name
+	}
+
+	logging.Instance().Debugf("rebasing commits after %s onto %s", upstream, onto)
+
+	return r.resolveRebase(r.backend.RebaseOnto(onto, upstream))
+}
+
+func (r *Repository) ContinueRebase() error {
+	return r.resolveRebase(r.backend.ContinueRebase())
+}
+
+func (r *Repository) RebaseInProgress() bool {
+	return r.backend.RebaseInProgress()
+}
+
+func (r *Repository) Conflicts() []string {
+	conflicts, err := r.backend.ConflictedFiles()
 	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stdout))
+		logging.Instance().Debugf("failed to list conflicted files. %v", err)
+		return nil
+	}
+	return conflicts
+}
+
+func (r *Repository) resolveRebase(err error) error {
+	// stacked features carry their own GOG metadata which conflicts with the parent's removal of it
+	for err != nil {
+		conflicts := r.Conflicts()
+		if len(conflicts) == 0 || !onlyGOGMetadata(conflicts) {
+			return err
+		}
+
+		logging.Instance().Debugf("resolving GOG metadata conflicts in favour of the current feature: %v", conflicts)
+
+		if err := r.backend.Stage(conflicts...); err != nil {
+			return err
+		}
+
+		err = r.backend.ContinueRebase()
 	}
 
 	return nil
 }
 
+func (r *Repository) HeadCommit() (string, error) {
+	return r.backend.Head()
+}
+
+func (r *Repository) SquashMerge() error {
+	return r.backend.SquashMerge(r.FeatureBranch.Name)
+}
+
 func (r *Repository) LogN(N int) ([]string, error) {
 	logging.Instance().Debugf("capturing previous %d commits from git log", N)
 
-	cmd := exec.Command("git", "log", "-" + fmt.Sprint(N), "--pretty=%B")
-	stdout, err := cmd.CombinedOutput()
+	log, err := r.backend.Commits(N)
 	if err != nil {
 		return nil, err
 	}
 
 	var commits []string
-	for _, m := range strings.Split(string(stdout), "\n") {
-		if m != "" {
-			commits = append(commits, m)
+	for _, c := range log {
+		for _, m := range strings.Split(c.Message(), "\n") {
+			if m != "" {
+				commits = append(commits, m)
+			}
 		}
 	}
 
@@ -300,24 +382,20 @@ func (r *Repository) LogN(N int) ([]string, error) {
 	return commits, nil
 }
 
-func (r *Repository) CreateTag(name, message string, force bool) error {
+func (r *Repository) CreateTag(name, message string, force bool, signing Signing) error {
 	if r.CurrentBranch.Name != r.DefaultBranch.Name {
 		logging.Instance().Warnf("creating tag based on a non-default branch. it is recommended to only create tags from a base branch. current branch: %s", r.CurrentBranch.Name)
 	}
 
-	var tagCmd *exec.Cmd
-	if force {
-		tagCmd = exec.Command("git", "tag", "-a", name, "--force", "-m", message)
-	} else {
-		tagCmd = exec.Command("git", "tag", "-a", name, "-m", message)
+	if signing.Sign {
+		logging.Instance().Debugf("signing tag %s (format: '%s', key: '%s')", name, signing.Format, signing.Key)
 	}
 
-	stdout, err := tagCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%v. %s", err, common.CleanstdoutMultiline(stdout))
-	}
+	return r.backend.CreateTag(name, message, force, signing)
+}
 
-	return nil
+func (r *Repository) VerifyTag(name string) (Signature, error) {
+	return r.backend.VerifyTag(name)
 }
 
 func (r *Repository) String() string {
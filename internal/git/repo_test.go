@@ -0,0 +1,101 @@
+package git
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain keeps the tests away from the user's GOG config and any git project around the working directory
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gog-git-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("GIT_CEILING_DIRECTORIES", dir)
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// commit adds a commit with message on the current branch of the memory backend
+func commit(t *testing.T, m *MemoryBackend, message string) {
+	t.Helper()
+
+	m.Touch()
+	if err := m.StageAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Commit(message); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewRepositoryWithBackend(t *testing.T) {
+	m := NewMemoryBackend("/work/app", "main")
+	commit(t, m, "first release")
+	if err := m.CreateTag("v1.2.0", "(v1.2.0): first release", false, Signing{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Push("main", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Checkout("ABC-1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRepositoryWithBackend(m)
+	if err != nil {
+		t.Fatalf("NewRepositoryWithBackend failed. %v", err)
+	}
+
+	if r.Name != "app" {
+		t.Errorf("Name = %s, want app", r.Name)
+	}
+	if r.DefaultBranch.Name != "main" || r.CurrentBranch.Name != "ABC-1" {
+		t.Errorf("default and current branch = %s, %s, want main, ABC-1", r.DefaultBranch, r.CurrentBranch)
+	}
+	if !r.DefaultBranch.RemoteExists || r.CurrentBranch.RemoteExists || !r.CurrentBranch.LocalExists {
+		t.Errorf("unexpected branch existence %+v %+v", r.DefaultBranch, r.CurrentBranch)
+	}
+	if r.LastTag.String() != "v1.2.0" || r.VersionPrefix != "v" {
+		t.Errorf("last tag and prefix = %s, '%s', want v1.2.0, 'v'", r.LastTag, r.VersionPrefix)
+	}
+}
+
+func TestRepositoryTags(t *testing.T) {
+	m := NewMemoryBackend("/work/app", "main")
+	commit(t, m, "release")
+
+	r, err := NewRepositoryWithBackend(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.CreateTag("v1.0.0", "(v1.0.0): release", false, Signing{Sign: true, Format: "ssh", Key: "key"}); err != nil {
+		t.Fatalf("CreateTag failed. %v", err)
+	}
+	if err := r.CreateTag("v1.0.0", "again", false, Signing{}); err == nil {
+		t.Error("CreateTag should not replace an existing tag without force")
+	}
+
+	signature, err := r.VerifyTag("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signature.Signed() || !signature.Valid || signature.Format != "ssh" {
+		t.Errorf("unexpected signature %+v", signature)
+	}
+
+	tags, err := r.VersionTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "v1.0.0" {
+		t.Errorf("VersionTags = %v, want [v1.0.0]", tags)
+	}
+}
@@ -0,0 +1,287 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sykesdev.ca/gog/internal/git"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+type FileBackup struct {
+	Exists bool `json:"exists"`
+	Dir bool `json:"dir,omitempty"`
+	Content []byte `json:"content,omitempty"`
+}
+
+type Journal struct {
+	Root string `json:"root"`
+
+	FeatureBranch string `json:"feature_branch"`
+	FeatureCommit string `json:"feature_commit"`
+	DefaultBranch string `json:"default_branch"`
+	DefaultCommit string `json:"default_commit"`
+	WorkingChanges string `json:"working_changes,omitempty"`
+
+	Files map[string]FileBackup `json:"files"`
+	Tags map[string]string `json:"tags,omitempty"`
+
+	Completed []string `json:"completed"`
+	Paused string `json:"paused,omitempty"`
+	Remote []string `json:"remote,omitempty"`
+}
+
+func New(r *git.Repository) (*Journal, error) {
+	root, err := r.Backend().ProjectRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine project root. %v", err)
+	}
+
+	featureCommit, err := r.HeadCommit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture current commit for %s. %v", r.FeatureBranch, err)
+	}
+
+	j := &Journal{
+		Root: root,
+		FeatureBranch: r.FeatureBranch.Name,
+		FeatureCommit: featureCommit,
+		DefaultBranch: r.DefaultBranch.Name,
+		Files: make(map[string]FileBackup),
+		Tags: make(map[string]string),
+	}
+
+	if defaultCommit, err := r.Backend().RevParse("refs/heads/" + r.DefaultBranch.Name); err == nil {
+		j.DefaultCommit = defaultCommit
+	}
+
+	j.WorkingChanges, err = r.Backend().StashCreate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot working changes for %s. %v", r.FeatureBranch, err)
+	}
+
+	untracked, err := r.Backend().UntrackedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files for %s. %v", r.FeatureBranch, err)
+	}
+	for _, f := range untracked {
+		if err := j.Backup(f); err != nil {
+			return nil, err
+		}
+	}
+
+	logging.Instance().Debugf("started finish journal for %s at %s (working changes: '%s')", j.FeatureBranch, j.FeatureCommit, j.WorkingChanges)
+
+	return j, nil
+}
+
+func (j *Journal) path(file string) string {
+	return filepath.Join(j.Root, file)
+}
+
+func (j *Journal) Backup(file string) error {
+	if j.Files == nil {
+		j.Files = make(map[string]FileBackup)
+	}
+
+	info, err := os.Stat(j.path(file))
+	if os.IsNotExist(err) {
+		j.Files[file] = FileBackup{Exists: false}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to back up %s. %v", file, err)
+	}
+
+	if info.IsDir() {
+		j.Files[file] = FileBackup{Exists: true, Dir: true}
+		return filepath.Walk(j.path(file), func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(j.Root, path)
+			if err != nil {
+				return err
+			}
+			return j.Backup(rel)
+		})
+	}
+
+	content, err := os.ReadFile(j.path(file))
+	if err != nil {
+		return fmt.Errorf("failed to back up %s. %v", file, err)
+	}
+	j.Files[file] = FileBackup{Exists: true, Content: content}
+
+	logging.Instance().Debugf("backed up %s (%d bytes) to finish journal", file, len(content))
+
+	return nil
+}
+
+func (j *Journal) Complete(step string) {
+	j.Completed = append(j.Completed, step)
+	logging.Instance().Debugf("finish journal step completed: %s", step)
+}
+
+func (j *Journal) CompleteRemote(step, description string) {
+	j.Complete(step)
+	j.Remote = append(j.Remote, description)
+}
+
+func (j *Journal) Done(step string) bool {
+	for _, s := range j.Completed {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *Journal) RecordTag(r *git.Repository, name string) {
+	if j.Tags == nil {
+		j.Tags = make(map[string]string)
+	}
+
+	if _, ok := j.Tags[name]; ok {
+		return
+	}
+
+	previous, err := r.Backend().RevParse("refs/tags/" + name)
+	if err != nil {
+		previous = ""
+	}
+	j.Tags[name] = previous
+
+	logging.Instance().Debugf("recorded tag %s with previous target '%s' in finish journal", name, previous)
+}
+
+func (j *Journal) restoreFiles() error {
+	var errs []string
+
+	for file, backup := range j.Files {
+		if backup.Dir {
+			// files added to a backed up directory since (eg. a pending release in .gog) were not there before
+			if err := j.removeAdded(file); err != nil {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+
+		if !backup.Exists {
+			if err := os.RemoveAll(j.path(file)); err != nil {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(j.path(file)), 0755); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if err := os.WriteFile(j.path(file), backup.Content, 0644); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		logging.Instance().Debugf("restored %s from finish journal", file)
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (j *Journal) removeAdded(dir string) error {
+	var added []string
+
+	err := filepath.Walk(j.path(dir), func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(j.Root, path)
+		if err != nil {
+			return err
+		}
+		if _, ok := j.Files[rel]; !ok {
+			added = append(added, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range added {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		logging.Instance().Debugf("removed %s which was added since the finish journal was started", path)
+	}
+	return nil
+}
+
+func (j *Journal) Rollback(r *git.Repository) error {
+	b := r.Backend()
+
+	logging.Instance().Debugf("rolling back finish journal with completed steps: %v", j.Completed)
+
+	if b.RebaseInProgress() {
+		if err := b.AbortRebase(); err != nil {
+			return fmt.Errorf("failed to abort in-progress rebase. %v", err)
+		}
+	}
+
+	if err := b.ResetHard("HEAD"); err != nil {
+		return fmt.Errorf("failed to discard partial changes. %v", err)
+	}
+
+	if err := b.CheckoutAt(j.FeatureBranch, j.FeatureCommit); err != nil {
+		return fmt.Errorf("failed to restore %s to %s. %v", j.FeatureBranch, j.FeatureCommit, err)
+	}
+
+	if j.DefaultCommit != "" && !j.Done("push-release") {
+		if err := b.UpdateRef("refs/heads/" + j.DefaultBranch, j.DefaultCommit); err != nil {
+			return fmt.Errorf("failed to restore %s to %s. %v", j.DefaultBranch, j.DefaultCommit, err)
+		}
+	}
+
+	if j.WorkingChanges != "" {
+		if err := b.StashApply(j.WorkingChanges); err != nil {
+			return fmt.Errorf("failed to restore working changes from %s. %v", j.WorkingChanges, err)
+		}
+	}
+
+	if err := j.restoreFiles(); err != nil {
+		return fmt.Errorf("failed to restore backed up files. %v", err)
+	}
+
+	if !j.Done("push-tags") {
+		for t, previous := range j.Tags {
+			if previous != "" {
+				if err := b.UpdateRef("refs/tags/" + t, previous); err != nil {
+					logging.Instance().Debugf("failed to restore local tag %s during rollback. %v", t, err)
+				}
+				continue
+			}
+
+			if err := b.DeleteTag(t); err != nil {
+				logging.Instance().Debugf("failed to delete local tag %s during rollback. %v", t, err)
+				continue
+			}
+			logging.Instance().Debugf("deleted local tag %s during rollback", t)
+		}
+	}
+
+	r.CurrentBranch = r.NewBranch(j.FeatureBranch)
+	r.FeatureBranch = r.NewBranch(j.FeatureBranch)
+
+	return nil
+}
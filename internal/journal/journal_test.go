@@ -0,0 +1,221 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sykesdev.ca/gog/internal/git"
+)
+
+// TestMain keeps the tests away from the user's GOG config and any git project around the working directory
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "gog-journal-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("GIT_CEILING_DIRECTORIES", dir)
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func commit(t *testing.T, m *git.MemoryBackend, message string) {
+	t.Helper()
+
+	m.Touch()
+	if err := m.StageAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Commit(message); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFile(t *testing.T, root, file, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(root, file)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(root, file string) (string, bool) {
+	content, err := os.ReadFile(filepath.Join(root, file))
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// finishFeature starts a journal on the feature branch ABC-1 and makes the changes finish would, up to the steps in completed
+func finishFeature(t *testing.T, completed []string) (*git.Repository, *git.MemoryBackend, *Journal, string, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	m := git.NewMemoryBackend(root, "main")
+	if err := m.CreateTag("v1.0.0", "(v1.0.0): first release", false, git.Signing{}); err != nil {
+		t.Fatal(err)
+	}
+	defaultCommit, _ := m.Head()
+
+	if err := m.Checkout("ABC-1", true); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, m, "ABC-1 feat: login")
+	featureCommit, _ := m.Head()
+
+	writeFile(t, root, ".gog/feature.json", `{"ticket":"ABC-1"}`)
+	writeFile(t, root, "CHANGELOG.md", "# Changelog\n")
+
+	r, err := git.NewRepositoryWithBackend(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	*r.FeatureBranch = *r.CurrentBranch
+
+	j, err := New(r)
+	if err != nil {
+		t.Fatalf("New failed. %v", err)
+	}
+	for _, file := range []string{".gog", "CHANGELOG.md"} {
+		if err := j.Backup(file); err != nil {
+			t.Fatalf("Backup(%s) failed. %v", file, err)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(root, ".gog/feature.json")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, root, ".gog/releases/ABC-1.json", `{}`)
+	writeFile(t, root, "CHANGELOG.md", "# Changelog\n\n## [v1.1.0]\n")
+
+	if err := m.Checkout("main", false); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, m, "ABC-1 login")
+	j.RecordTag(r, "v1.1.0")
+	j.RecordTag(r, "v1.0.0")
+	if err := m.CreateTag("v1.1.0", "(v1.1.0): login", false, git.Signing{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateTag("v1.0.0", "moved", true, git.Signing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range completed {
+		j.Complete(step)
+	}
+
+	return r, m, j, defaultCommit, featureCommit
+}
+
+func TestRollback(t *testing.T) {
+	tests := []struct {
+		name string
+		completed []string
+		restoresDefault bool
+		restoresTags bool
+	}{
+		{"before anything was pushed", []string{"squash-merge", "commit-release", "create-tags"}, true, true},
+		{"after the release commit was pushed", []string{"create-tags", "push-release"}, false, true},
+		{"after the tags were pushed", []string{"create-tags", "push-release", "push-tags"}, false, false},
+	}
+
+	for _, tt := range tests {
+		r, m, j, defaultCommit, featureCommit := finishFeature(t, tt.completed)
+		releaseCommit, _ := m.RevParse("refs/heads/main")
+
+		if err := j.Rollback(r); err != nil {
+			t.Fatalf("%s: Rollback failed. %v", tt.name, err)
+		}
+
+		if current, _ := m.CurrentBranch(); current != "ABC-1" || r.CurrentBranch.Name != "ABC-1" {
+			t.Errorf("%s: current branch = %s, want ABC-1", tt.name, current)
+		}
+		if head, _ := m.Head(); head != featureCommit {
+			t.Errorf("%s: ABC-1 = %s, want %s", tt.name, head, featureCommit)
+		}
+
+		wantDefault := releaseCommit
+		if tt.restoresDefault {
+			wantDefault = defaultCommit
+		}
+		if got, _ := m.RevParse("refs/heads/main"); got != wantDefault {
+			t.Errorf("%s: main = %s, want %s", tt.name, got, wantDefault)
+		}
+
+		_, tagged := m.RevParse("refs/tags/v1.1.0")
+		if tagged != nil != tt.restoresTags {
+			t.Errorf("%s: v1.1.0 exists = %t, want %t", tt.name, tagged == nil, !tt.restoresTags)
+		}
+		if previous, _ := m.RevParse("refs/tags/v1.0.0"); tt.restoresTags && previous != defaultCommit {
+			t.Errorf("%s: v1.0.0 = %s, want it moved back to %s", tt.name, previous, defaultCommit)
+		}
+
+		if content, ok := readFile(j.Root, ".gog/feature.json"); !ok || content != `{"ticket":"ABC-1"}` {
+			t.Errorf("%s: .gog/feature.json = %q, %t, want it restored", tt.name, content, ok)
+		}
+		if _, ok := readFile(j.Root, ".gog/releases/ABC-1.json"); ok {
+			t.Errorf("%s: .gog/releases/ABC-1.json should have been removed", tt.name)
+		}
+		if content, _ := readFile(j.Root, "CHANGELOG.md"); content != "# Changelog\n" {
+			t.Errorf("%s: CHANGELOG.md = %q, want it restored", tt.name, content)
+		}
+	}
+}
+
+func TestBackupMissingFile(t *testing.T) {
+	root := t.TempDir()
+	j := &Journal{Root: root}
+
+	if err := j.Backup("CHANGELOG.md"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, root, "CHANGELOG.md", "# Changelog\n")
+
+	if err := j.restoreFiles(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readFile(root, "CHANGELOG.md"); ok {
+		t.Error("a file which did not exist when it was backed up should be removed on restore")
+	}
+}
+
+// the journal is saved with the finish and release progress, so it must survive a round trip through JSON
+func TestJournalJSON(t *testing.T) {
+	_, _, j, _, _ := finishFeature(t, []string{"create-tags"})
+	j.CompleteRemote("push-release", "pushed release commit to origin/main")
+
+	saved, err := json.Marshal(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded *Journal
+	if err := json.Unmarshal(saved, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !loaded.Done("create-tags") || !loaded.Done("push-release") || loaded.Done("push-tags") {
+		t.Errorf("completed steps = %v", loaded.Completed)
+	}
+	if len(loaded.Remote) != 1 || loaded.FeatureBranch != "ABC-1" || loaded.DefaultCommit != j.DefaultCommit {
+		t.Errorf("loaded journal %+v, want %+v", loaded, j)
+	}
+	if backup := loaded.Files[".gog/feature.json"]; !backup.Exists || string(backup.Content) != `{"ticket":"ABC-1"}` {
+		t.Errorf(".gog/feature.json backup = %+v", backup)
+	}
+	if _, ok := loaded.Tags["v1.1.0"]; !ok || loaded.Tags["v1.0.0"] != j.Tags["v1.0.0"] {
+		t.Errorf("tags = %v, want %v", loaded.Tags, j.Tags)
+	}
+}
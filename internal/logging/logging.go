@@ -2,6 +2,7 @@ package logging
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strings"
@@ -13,6 +14,8 @@ import (
 
 type Logging struct {
 	Level string
+
+	out io.Writer
 }
 
 var (
@@ -46,6 +49,18 @@ func Instance() *Logging {
 	return &instance
 }
 
+// SetOutput redirects log lines, eg. to stderr when stdout is reserved for JSON output
+func (l *Logging) SetOutput(w io.Writer) {
+	l.out = w
+}
+
+func (l Logging) writer() io.Writer {
+	if l.out == nil {
+		return os.Stdout
+	}
+	return l.out
+}
+
 func (l *Logging) Setup(level string) {
 	if common.StringInSlice(SeverityLevels, strings.ToUpper(level)) {
 		l.Level = strings.ToUpper(level)
@@ -53,7 +68,7 @@ func (l *Logging) Setup(level string) {
 }
 
 func (l Logging) Info(message string) {
-	fmt.Printf("%v-%v-%v %v:%v:%v [INFO] - %v\n",
+	fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [INFO] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -64,7 +79,7 @@ func (l Logging) Info(message string) {
 }
 
 func (l Logging) Infof(fmtMessage string, arguments ...interface{}) {
-	fmt.Printf("%v-%v-%v %v:%v:%v [INFO] - %v\n",
+	fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [INFO] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -76,7 +91,7 @@ func (l Logging) Infof(fmtMessage string, arguments ...interface{}) {
 
 func (l Logging) Warn(message string) {
 	if l.Level == "WARN" || l.Level == "DEBUG" || l.Level == "INFO" {
-		fmt.Printf("%v-%v-%v %v:%v:%v [WARN] - %v\n",
+		fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [WARN] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -89,7 +104,7 @@ func (l Logging) Warn(message string) {
 
 func (l Logging) Warnf(fmtMessage string, arguments ...interface{}) {
 	if l.Level == "WARN" || l.Level == "DEBUG" || l.Level == "INFO" {
-		fmt.Printf("%v-%v-%v %v:%v:%v [WARN] - %v\n",
+		fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [WARN] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -102,7 +117,7 @@ func (l Logging) Warnf(fmtMessage string, arguments ...interface{}) {
 
 func (l Logging) Debug(message string) {
 	if l.Level == "DEBUG" {
-		fmt.Printf("%v-%v-%v %v:%v:%v [%s] [DEBUG] - %v\n",
+		fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [%s] [DEBUG] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -116,7 +131,7 @@ func (l Logging) Debug(message string) {
 
 func (l Logging) Debugf(fmtMessage string, arguments ...interface{}) {
 	if l.Level == "DEBUG" {
-		fmt.Printf("%v-%v-%v %v:%v:%v [%s] [DEBUG] - %v\n",
+		fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [%s] [DEBUG] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -129,7 +144,7 @@ func (l Logging) Debugf(fmtMessage string, arguments ...interface{}) {
 }
 
 func (l Logging) Error(message string) {
-	fmt.Printf("%v-%v-%v %v:%v:%v [%s] [ERROR] - %v\n",
+	fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [%s] [ERROR] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -141,7 +156,7 @@ func (l Logging) Error(message string) {
 }
 
 func (l Logging) Errorf(fmtMessage string, arguments ...interface{}) {
-	fmt.Printf("%v-%v-%v %v:%v:%v [%s] [ERROR] - %v\n",
+	fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [%s] [ERROR] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -153,7 +168,7 @@ func (l Logging) Errorf(fmtMessage string, arguments ...interface{}) {
 }
 
 func (l Logging) Fatal(message string) {
-	fmt.Printf("%v-%v-%v %v:%v:%v [%s] [FATAL] - %v\n",
+	fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [%s] [FATAL] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
@@ -166,7 +181,7 @@ func (l Logging) Fatal(message string) {
 }
 
 func (l Logging) Fatalf(fmtMessage string, arguments ...interface{}) {
-	fmt.Printf("%v-%v-%v %v:%v:%v [%s] [FATAL] - %v\n",
+	fmt.Fprintf(l.writer(), "%v-%v-%v %v:%v:%v [%s] [FATAL] - %v\n",
 		time.Now().Year(),
 		int(time.Now().Month()),
 		time.Now().Day(),
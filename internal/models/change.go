@@ -0,0 +1,28 @@
+package models
+
+import (
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/conventional"
+	"sykesdev.ca/gog/internal/git"
+)
+
+// Change is a commit listed in the changelog and the Keep a Changelog section it belongs in. an empty category is left to the changelog entry to decide
+type Change struct {
+	Category string `json:"category,omitempty"`
+	Hash string `json:"hash,omitempty"`
+	ShortHash string `json:"short_hash"`
+	Author string `json:"author,omitempty"`
+	Subject string `json:"subject"`
+}
+
+// NewChange categorizes c by its 'Changelog:' trailer, falling back to its conventional commit type
+func NewChange(c git.Commit, ticket string) Change {
+	parsed := conventional.Parse(c, ticket)
+
+	category, ok := config.AppConfig().ChangelogCategory(parsed.Changelog)
+	if !ok && parsed.Conventional {
+		category, _ = config.AppConfig().ChangelogCategory(parsed.Type)
+	}
+
+	return Change{Category: category, Hash: c.Hash, ShortHash: c.ShortHash, Author: c.Author, Subject: c.Subject}
+}
@@ -1,30 +1,35 @@
 package models
 
 import (
-	"bufio"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"regexp"
 	"strings"
+	"text/template"
 
+	"sykesdev.ca/gog/config"
 	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/common/constants"
 	"sykesdev.ca/gog/internal/git"
 	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
 	"sykesdev.ca/gog/internal/semver"
 )
 
 type Feature struct {
-	Jira string `json:"jira"`
+	Ticket string `json:"ticket"`
 	Comment string `json:"comment"`
 	CustomVersionPrefix string `json:"custom_prefix"`
+	Component string `json:"component,omitempty"`
 	TestCount int `json:"test_count"`
+	ParentFeature string `json:"parent_feature,omitempty"`
+	ParentCommit string `json:"parent_commit,omitempty"`
 }
 
-func NewFeature(jira, comment, versionPrefix string) (*Feature, error) {
-	feat := &Feature{Jira: jira, Comment: comment, TestCount: 0}
+func NewFeature(ticket, comment, versionPrefix string) (*Feature, error) {
+	feat := &Feature{Ticket: ticket, Comment: comment, TestCount: 0}
 
 	if versionPrefix != "" {
 		if matched, _ := regexp.MatchString(constants.VersionPrefixRegexp, versionPrefix); !matched {
@@ -38,6 +43,25 @@ func NewFeature(jira, comment, versionPrefix string) (*Feature, error) {
 	return feat, nil
 }
 
+// UnmarshalJSON also accepts the 'jira' key written by older versions of GOG
+func (f *Feature) UnmarshalJSON(data []byte) error {
+	type feature Feature
+	legacy := struct {
+		*feature
+		Jira string `json:"jira"`
+	}{feature: (*feature)(f)}
+
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	if f.Ticket == "" {
+		f.Ticket = legacy.Jira
+	}
+
+	return nil
+}
+
 func NewFeatureFromFile() (*Feature, error) {
 	GOGDir := common.GOGPath()
 	
@@ -57,6 +81,24 @@ func NewFeatureFromFile() (*Feature, error) {
 	return feature, nil
 }
 
+func (f *Feature) SetParent(parent *Feature, commit string) {
+	f.ParentFeature = parent.Ticket
+	f.ParentCommit = commit
+
+	if f.CustomVersionPrefix == "" {
+		f.CustomVersionPrefix = parent.CustomVersionPrefix
+	}
+	if f.Component == "" {
+		f.Component = parent.Component
+	}
+
+	logging.Instance().Debugf("feature %s stacked on parent feature %s at commit %s", f.Ticket, f.ParentFeature, f.ParentCommit)
+}
+
+func (f *Feature) IsStacked() bool {
+	return f.ParentFeature != ""
+}
+
 func (f *Feature) UpdateTestCount() error {
 	f.TestCount += 1
 	
@@ -69,39 +111,81 @@ func (f *Feature) UpdateTestCount() error {
 	return nil
 }
 
-func (f *Feature) CreateReleaseTags(r *git.Repository, version semver.Semver) error {
-	tagMessage := fmt.Sprintf("(%s): %s %s", version, f.Jira, f.Comment)
+// DefaultTagMessageTemplate is the message of release tags unless release.tag_message is configured. 'gog changelog rebuild' reads the feature back from its first line
+const DefaultTagMessageTemplate = `({{ .Tag }}): {{ .Ticket }} {{ .Comment }}`
+
+// TagMessageData is the data the tag message template is rendered with
+type TagMessageData struct {
+	// Tag is the tag being created, which is the release version or its major version tag (eg. 'v1')
+	Tag string
+	Version string
+	Ticket string
+	Comment string
+	// Changelog is the changelog entry of the release in markdown. it is empty when there is no entry (eg. with -no-changelog)
+	Changelog string
+}
+
+// TagMessage renders the release tag message for tag
+func (f *Feature) TagMessage(tag string, version semver.Semver, changelog string) (string, error) {
+	text := DefaultTagMessageTemplate
+	if configured := config.AppConfig().TagMessage(); configured != "" {
+		text = configured
+	}
+
+	t, err := template.New("tag_message").Parse(text)
+	if err != nil {
+		return "", output.Errorf(output.CodeConfig, "invalid release.tag_message. %v", err)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, TagMessageData{Tag: tag, Version: version.String(), Ticket: f.Ticket, Comment: f.Comment, Changelog: strings.TrimSpace(changelog)}); err != nil {
+		return "", output.Errorf(output.CodeConfig, "failed to render release.tag_message. %v", err)
+	}
+	return b.String(), nil
+}
+
+func (f *Feature) CreateReleaseTags(r *git.Repository, version semver.Semver, changelog string, signing git.Signing) error {
+	tagMessage, err := f.TagMessage(version.String(), version, changelog)
+	if err != nil {
+		return err
+	}
 
 	logging.Instance().Debugf("creating release tag with message: %s", tagMessage)
 
-	err := r.CreateTag(version.String(), tagMessage, false)
+	err = r.CreateTag(version.String(), tagMessage, false, signing)
 	if err != nil {
 		return err
 	}
 
 	logging.Instance().Debugf("created release tag (%s) for feature: %s", version, f)
 
-	tagMessage = fmt.Sprintf("(%s): %s %s", version.Major(), f.Jira, f.Comment)
-	err = r.CreateTag(version.Major(), tagMessage, true)
+	if version.IsPreRelease() {
+		logging.Instance().Debugf("skipping major version tag (%s) for pre-release %s", version.Major(), version)
+		return nil
+	}
+
+	tagMessage, err = f.TagMessage(version.Major(), version, changelog)
+	if err != nil {
+		return err
+	}
+	err = r.CreateTag(version.Major(), tagMessage, true, signing)
 
 	logging.Instance().Debugf("created release tag (%s) for feature: %s", version.Major(), f)
 	
 	return err
 }
 
-func (f *Feature) Changes(r *git.Repository) ([]string, error) {
-	var changes []string
-	changeBlob, err := r.FeatureBranch.RelatedLogs()
+func (f *Feature) Changes(r *git.Repository) ([]Change, error) {
+	var changes []Change
+	commits, err := r.FeatureBranch.RelatedCommits()
 	if err != nil {
 		return nil, err
 	}
 
-	logging.Instance().Debugf("got change blob from git logs containing: %s", changeBlob)
 	logging.Instance().Debug("creating formatted change log entries for feature")
 
-	scanner := bufio.NewScanner(strings.NewReader(string(changeBlob)))
-	for scanner.Scan() {
-		changes = append(changes, fmt.Sprintf("- %s", scanner.Text()))
+	for _, c := range commits {
+		changes = append(changes, NewChange(c, f.Ticket))
 	}
 
 	logging.Instance().Debugf("feature changes captured: %v", changes)
@@ -144,5 +228,5 @@ func (f *Feature) Save() error {
 }
 
 func (f *Feature) String() string {
-	return fmt.Sprintf("%s %s", f.Jira, f.Comment)
+	return fmt.Sprintf("%s %s", f.Ticket, f.Comment)
 }
\ No newline at end of file
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+// PendingRelease records a feature finished through a pull request, to be tagged by 'gog release' once merged
+type PendingRelease struct {
+	Feature *Feature `json:"feature"`
+	Action string `json:"action"`
+	PreRelease string `json:"pre_release,omitempty"`
+	NoChangelog bool `json:"no_changelog"`
+	NoTag bool `json:"no_tag"`
+	Publish bool `json:"publish,omitempty"`
+	Sign bool `json:"sign,omitempty"`
+	Changes []Change `json:"changes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func pendingReleasesPath() string {
+	return filepath.Join(common.GOGPath(), "releases")
+}
+
+func NewPendingRelease(feature *Feature, action, preRelease string, noChangelog, noTag bool, changes []Change) *PendingRelease {
+	return &PendingRelease{
+		Feature: feature,
+		Action: action,
+		PreRelease: preRelease,
+		NoChangelog: noChangelog,
+		NoTag: noTag,
+		Changes: changes,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// PendingReleases loads all pending releases, oldest first
+func PendingReleases() ([]*PendingRelease, error) {
+	entries, err := os.ReadDir(pendingReleasesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []*PendingRelease
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		release, err := LoadPendingRelease(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].CreatedAt.Before(releases[j].CreatedAt)
+	})
+
+	logging.Instance().Debugf("loaded %d pending release(s) from %s", len(releases), pendingReleasesPath())
+
+	return releases, nil
+}
+
+func LoadPendingRelease(ticket string) (*PendingRelease, error) {
+	releaseBytes, err := os.ReadFile(filepath.Join(pendingReleasesPath(), ticket + ".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var release *PendingRelease
+	if err := json.Unmarshal(releaseBytes, &release); err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+func (p *PendingRelease) path() string {
+	return filepath.Join(pendingReleasesPath(), p.Feature.Ticket + ".json")
+}
+
+func (p *PendingRelease) Save() error {
+	if err := os.MkdirAll(pendingReleasesPath(), 0755); err != nil {
+		return err
+	}
+
+	releaseBytes, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	logging.Instance().Debugf("saving pending release for %s to %s", p.Feature, p.path())
+
+	return os.WriteFile(p.path(), releaseBytes, 0644)
+}
+
+// Remove deletes the pending release, along with the GOG directory once nothing else is left in it
+func (p *PendingRelease) Remove() error {
+	if err := os.Remove(p.path()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, dir := range []string{pendingReleasesPath(), common.GOGPath()} {
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
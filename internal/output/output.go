@@ -0,0 +1,125 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var Formats = []string{FormatText, FormatJSON}
+
+// error codes are part of the JSON output and must not change once released
+const (
+	CodeUsage = "usage"
+	CodeNotRepository = "not_a_repository"
+	CodeNoFeature = "no_feature"
+	CodeInvalidTicket = "invalid_ticket"
+	CodeBranchExists = "branch_exists"
+	CodeUncommittedChanges = "uncommitted_changes"
+	CodeInProgress = "release_in_progress"
+	CodeConflict = "conflict"
+	CodeGit = "git_failed"
+	CodeForge = "forge_failed"
+	CodeConfig = "invalid_config"
+	CodeUpdate = "update_failed"
+	CodeVerify = "verify_failed"
+	CodeInputRequired = "input_required"
+	CodeUnknown = "unknown"
+)
+
+var format = FormatText
+
+func SetFormat(f string) error {
+	f = strings.ToLower(f)
+	if f != FormatText && f != FormatJSON {
+		return Errorf(CodeUsage, "unknown output format '%s'. must be one of %s", f, strings.Join(Formats, ", "))
+	}
+
+	format = f
+	return nil
+}
+
+// JSON reports if results should be printed as JSON instead of human-readable text
+func JSON() bool {
+	return format == FormatJSON
+}
+
+// Error is a failure with a stable code which callers can branch on
+type Error struct {
+	Code string
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func Errorf(code, format string, args ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// WithCode attaches a code to err, keeping any more specific code it already carries
+func WithCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var coded *Error
+	if errors.As(err, &coded) {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+func Code(err error) string {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return CodeUnknown
+}
+
+type errorBody struct {
+	Code string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Envelope is the JSON document printed for every command in JSON mode
+type Envelope struct {
+	Command string `json:"command"`
+	OK bool `json:"ok"`
+	Result interface{} `json:"result"`
+	Error *errorBody `json:"error,omitempty"`
+}
+
+// Print writes the result of command, or its error, as a JSON envelope
+func Print(w io.Writer, command string, result interface{}, err error) error {
+	envelope := Envelope{Command: command, OK: err == nil, Result: result}
+	if err != nil {
+		envelope.Error = &errorBody{Code: Code(err), Message: err.Error()}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(envelope)
+}
+
+// Stdout is where human-readable output (plans, reports, config values) goes. it is discarded in JSON mode so that only the envelope reaches stdout
+func Stdout() io.Writer {
+	if JSON() {
+		return io.Discard
+	}
+	return os.Stdout
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name string
+		result interface{}
+		err error
+		want string
+	}{
+		{"result", map[string]string{"version": "v1.3.0"}, nil, `{"command":"finish","ok":true,"result":{"version":"v1.3.0"}}`},
+		{"no result", nil, nil, `{"command":"finish","ok":true,"result":null}`},
+		{"coded error", nil, Errorf(CodeConflict, "paused by conflicts"), `{"command":"finish","ok":false,"result":null,"error":{"code":"conflict","message":"paused by conflicts"}}`},
+		{"wrapped coded error", nil, fmt.Errorf("failed to finish. %w", Errorf(CodeGit, "push rejected")), `{"command":"finish","ok":false,"result":null,"error":{"code":"git_failed","message":"failed to finish. push rejected"}}`},
+		{"plain error", nil, errors.New("boom"), `{"command":"finish","ok":false,"result":null,"error":{"code":"unknown","message":"boom"}}`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := Print(&buf, "finish", tt.result, tt.err); err != nil {
+			t.Fatalf("%s: Print failed. %v", tt.name, err)
+		}
+
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, buf.Bytes()); err != nil {
+			t.Fatalf("%s: Print wrote invalid JSON. %v", tt.name, err)
+		}
+		if got := compact.String(); got != tt.want {
+			t.Errorf("%s: Print = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	if WithCode(CodeGit, nil) != nil {
+		t.Error("WithCode(nil) should stay nil")
+	}
+	if got := Code(WithCode(CodeGit, errors.New("boom"))); got != CodeGit {
+		t.Errorf("Code = %s, want %s", got, CodeGit)
+	}
+	if got := Code(WithCode(CodeGit, Errorf(CodeForge, "boom"))); got != CodeForge {
+		t.Errorf("Code = %s, want the more specific %s kept", got, CodeForge)
+	}
+}
+
+func TestSetFormat(t *testing.T) {
+	defer SetFormat(FormatText)
+
+	if err := SetFormat("JSON"); err != nil || !JSON() {
+		t.Errorf("SetFormat(JSON) = %v, JSON() = %t", err, JSON())
+	}
+	if err := SetFormat("xml"); Code(err) != CodeUsage || !JSON() {
+		t.Errorf("SetFormat(xml) = %v, want a usage error leaving the format as it was", err)
+	}
+}
@@ -3,43 +3,137 @@ package prompt
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 
 	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/output"
 )
 
-func String(msg string) string {
-	var b []byte
-	r := bufio.NewReader(os.Stdin)
+// Default is the answer a confirmation falls back to when the user just presses enter or cannot be asked
+type Default int
 
-	for {
-		fmt.Fprintf(os.Stderr, "%s ", msg)
-		b, _ = r.ReadBytes('\n')
-		if len(b) > 0 {
-			break
+const (
+	NoDefault Default = iota
+	DefaultYes
+	DefaultNo
+)
+
+var (
+	assumeYes bool
+	noInput bool
+)
+
+// SetAssumeYes answers yes to every confirmation without asking (--yes)
+func SetAssumeYes(yes bool) {
+	assumeYes = yes
+}
+
+// SetNoInput stops GOG from reading stdin, falling back to declared defaults instead (--no-input)
+func SetNoInput(disabled bool) {
+	noInput = disabled
+}
+
+// Interactive reports if prompts can be shown. this is false with --yes, --no-input, GOG_NONINTERACTIVE or when stdin is not a terminal
+func Interactive() bool {
+	if assumeYes || noInput {
+		return false
+	}
+
+	if v := os.Getenv("GOG_NONINTERACTIVE"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err != nil || enabled {
+			return false
 		}
 	}
 
-	return common.CleanStdoutSingleline(b)
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
-func Int(msg string) (int, error) {
-	var i int64
-	var err error
-	r := bufio.NewReader(os.Stdin)
-
-	for {
-		fmt.Fprintf(os.Stderr, "%s ", msg)
-		s, _ := r.ReadString('\n')
-		i, err = strconv.ParseInt(s, 10, 0)
-		if err != nil {
-			return 0, err
+func inputRequired(msg string) error {
+	return output.Errorf(output.CodeInputRequired, "cannot ask '%s' in non-interactive mode. re-run with --yes to accept or from a terminal to answer", strings.TrimSpace(msg))
+}
+
+// Confirm asks a yes/no question. when prompts cannot be shown it uses def, and fails if there is none
+func Confirm(msg string, def Default) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+
+	if !Interactive() {
+		switch def {
+		case DefaultYes:
+			return true, nil
+		case DefaultNo:
+			return false, nil
 		}
-		if s != "" {
-			break
+		return false, inputRequired(msg)
+	}
+
+	hint := "(y/n)"
+	switch def {
+	case DefaultYes:
+		hint = "(Y/n)"
+	case DefaultNo:
+		hint = "(y/N)"
+	}
+
+	answer, err := read(fmt.Sprintf("%s %s?", msg, hint))
+	if err != nil {
+		if def == NoDefault {
+			return false, inputRequired(msg)
 		}
+		return def == DefaultYes, nil
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "":
+		return def == DefaultYes, nil
+	}
+	return false, nil
+}
+
+func read(msg string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s ", msg)
+
+	b, err := bufio.NewReader(os.Stdin).ReadBytes('\n')
+	if len(b) == 0 && err == io.EOF {
+		fmt.Fprintln(os.Stderr)
+		return "", err
+	}
+
+	return common.CleanStdoutSingleline(b), nil
+}
+
+func String(msg string) (string, error) {
+	if !Interactive() {
+		return "", inputRequired(msg)
+	}
+
+	s, err := read(msg)
+	if err != nil {
+		return "", inputRequired(msg)
+	}
+	return s, nil
+}
+
+func Int(msg string) (int, error) {
+	s, err := String(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := strconv.ParseInt(s, 10, 0)
+	if err != nil {
+		return 0, err
 	}
 
 	return int(i), nil
-}
\ No newline at end of file
+}
@@ -11,7 +11,15 @@ import (
 	"sykesdev.ca/gog/internal/common/constants"
 )
 
-type Semver [3]int
+type Semver struct {
+	Core [3]int
+	PreRelease string
+	Build string
+}
+
+var partsRegexp = regexp.MustCompile(`([0-9]+)\.([0-9]+)\.([0-9]+)(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
+var identifierRegexp = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+var numericRegexp = regexp.MustCompile(`^[0-9]+$`)
 
 func isValidSemver(versionString string) bool {
 	matched, _ := regexp.Match(constants.FullSemverRegexp, []byte(versionString))
@@ -31,63 +39,177 @@ func MustParse(versionString string) (Semver) {
 		panic("cannot parse version string since it is not in a valid semver format")
 	}
 
-	numReg := regexp.MustCompile(`[0-9]+`)
-	elements := strings.Split(string(versionString), ".")
-	major, err := strconv.Atoi(numReg.FindString(elements[0]))
-	if err != nil { major = 0 }
-	minor, err := strconv.Atoi(numReg.FindString(elements[1]))
-	if err != nil { minor = 0 }
-	patch, err := strconv.Atoi(numReg.FindString(elements[2]))
-	if err != nil { patch = 0 }
+	parts := partsRegexp.FindStringSubmatch(versionString)
+
+	var s Semver
+	for i := 0; i < 3; i++ {
+		n, err := strconv.Atoi(parts[i+1])
+		if err != nil { n = 0 }
+		s.Core[i] = n
+	}
+	s.PreRelease = parts[4]
+	s.Build = parts[5]
 
-	return [3]int{major, minor, patch}
+	return s
+}
+
+// ValidPreReleaseID reports if id can lead a pre-release, eg. 'rc'. it must be a single identifier, since BumpPreRelease
+// numbers the pre-releases after it, and numeric identifiers may not have leading zeros
+func ValidPreReleaseID(id string) bool {
+	if !identifierRegexp.MatchString(id) {
+		return false
+	}
+	return !numericRegexp.MatchString(id) || id == "0" || !strings.HasPrefix(id, "0")
 }
 
 func (s Semver) BumpMajor() Semver {
-	s[0] += 1
-	s[1], s[2] = 0, 0
+	s.Core[0] += 1
+	s.Core[1], s.Core[2] = 0, 0
+	s.PreRelease, s.Build = "", ""
 	return s
 }
 
 func (s Semver) BumpMinor() Semver {
-	s[1] += 1
-	s[2] = 0
+	s.Core[1] += 1
+	s.Core[2] = 0
+	s.PreRelease, s.Build = "", ""
 	return s
 }
 
 func (s Semver) BumpPatch() Semver {
-	s[2] += 1
+	s.Core[2] += 1
+	s.PreRelease, s.Build = "", ""
+	return s
+}
+
+func (s Semver) IsPreRelease() bool {
+	return s.PreRelease != ""
+}
+
+// Release drops any pre-release and build metadata, promoting a pre-release to its final version
+func (s Semver) Release() Semver {
+	s.PreRelease, s.Build = "", ""
+	return s
+}
+
+// PreReleaseID returns the leading pre-release identifier, eg. 'rc' for '1.3.0-rc.2'
+func (s Semver) PreReleaseID() string {
+	return strings.Split(s.PreRelease, ".")[0]
+}
+
+// BumpPreRelease moves to the next pre-release with the given identifier on the same core version, eg. 'rc.1' -> 'rc.2'
+func (s Semver) BumpPreRelease(id string) Semver {
+	s.Build = ""
+
+	if s.PreReleaseID() != id {
+		s.PreRelease = id + ".1"
+		return s
+	}
+
+	parts := strings.Split(s.PreRelease, ".")
+	if last := parts[len(parts) - 1]; len(parts) > 1 && numericRegexp.MatchString(last) {
+		n, _ := strconv.Atoi(last)
+		parts[len(parts) - 1] = strconv.Itoa(n + 1)
+	} else {
+		parts = append(parts, "1")
+	}
+	s.PreRelease = strings.Join(parts, ".")
+
 	return s
 }
 
 func (s Semver) Major() string {
-	return fmt.Sprintf("%s%v.x", config.AppConfig().TagPrefix(), s[0])
+	return fmt.Sprintf("%s%v.x", config.AppConfig().TagPrefix(), s.Core[0])
 }
 
 func (s Semver) String() string {
-	return fmt.Sprintf("%s%v.%v.%v", config.AppConfig().TagPrefix(), s[0], s[1], s[2])
+	return config.AppConfig().TagPrefix() + s.NoPrefix()
 }
 
 func (s Semver) NoPrefix() string {
-	return fmt.Sprintf("%v.%v.%v", s[0], s[1], s[2])
+	version := fmt.Sprintf("%v.%v.%v", s.Core[0], s.Core[1], s.Core[2])
+	if s.PreRelease != "" {
+		version += "-" + s.PreRelease
+	}
+	if s.Build != "" {
+		version += "+" + s.Build
+	}
+	return version
 }
 
-func (s Semver) Equal(o Semver) bool {
-	return s[0] == o[0] && s[1] == o[1] && s[2] == o[2]
+func (s Semver) MarshalText() ([]byte, error) {
+	return []byte(s.NoPrefix()), nil
 }
 
-func (s Semver) GreaterThan(o Semver) bool {
-	if s[0] > o[0] {
-		return true
+func (s *Semver) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = parsed
+	return nil
+}
+
+func comparePreRelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
 	}
-	
-	if s[0] == o[0] && s[1] > o[1] {
-		return true
+
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNumeric, bNumeric := numericRegexp.MatchString(aParts[i]), numericRegexp.MatchString(bParts[i])
+
+		switch {
+		case aNumeric && bNumeric:
+			aNum, _ := strconv.Atoi(aParts[i])
+			bNum, _ := strconv.Atoi(bParts[i])
+			if aNum != bNum {
+				if aNum > bNum { return 1 }
+				return -1
+			}
+		case aNumeric:
+			return -1
+		case bNumeric:
+			return 1
+		case aParts[i] != bParts[i]:
+			if aParts[i] > bParts[i] { return 1 }
+			return -1
+		}
 	}
 
-	if s[0] == o[0] && s[1] == o[1] && s[2] > o[2] {
-		return true
+	switch {
+	case len(aParts) > len(bParts):
+		return 1
+	case len(aParts) < len(bParts):
+		return -1
 	}
+	return 0
+}
+
+// Compare returns 1, 0 or -1 when s has a higher, equal or lower precedence than o. Build metadata is ignored
+func (s Semver) Compare(o Semver) int {
+	for i := 0; i < 3; i++ {
+		if s.Core[i] > o.Core[i] {
+			return 1
+		}
+		if s.Core[i] < o.Core[i] {
+			return -1
+		}
+	}
+
+	return comparePreRelease(s.PreRelease, o.PreRelease)
+}
 
-	return false
+func (s Semver) Equal(o Semver) bool {
+	return s.Compare(o) == 0
+}
+
+func (s Semver) GreaterThan(o Semver) bool {
+	return s.Compare(o) > 0
 }
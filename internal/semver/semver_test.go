@@ -0,0 +1,103 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		version string
+		want Semver
+		valid bool
+	}{
+		{"1.2.3", Semver{Core: [3]int{1, 2, 3}}, true},
+		{"v1.2.3", Semver{Core: [3]int{1, 2, 3}}, true},
+		{"1.3.0-rc.1", Semver{Core: [3]int{1, 3, 0}, PreRelease: "rc.1"}, true},
+		{"1.0.0-alpha+build.5", Semver{Core: [3]int{1, 0, 0}, PreRelease: "alpha", Build: "build.5"}, true},
+		{"1.0.0+20260101", Semver{Core: [3]int{1, 0, 0}, Build: "20260101"}, true},
+		{"1.2", Semver{}, false},
+		{"one.two.three", Semver{}, false},
+		{"", Semver{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.version)
+		if (err == nil) != tt.valid {
+			t.Errorf("Parse(%q) error = %v, want valid: %t", tt.version, err, tt.valid)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.3.0-rc.1", "1.3.0-rc.2", -1},
+		{"1.3.0-rc.2", "1.3.0-rc.10", -1},
+		{"1.3.0-rc.10", "1.3.0-rc.1", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0", "1.0.0-alpha", 1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-beta.1", "1.0.0-rc.1", -1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"1.0.0-rc.1+a", "1.0.0-rc.1", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "1.99.99", 1},
+	}
+
+	for _, tt := range tests {
+		if got := MustParse(tt.a).Compare(MustParse(tt.b)); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBumpPreRelease(t *testing.T) {
+	tests := []struct {
+		version string
+		id string
+		want string
+	}{
+		{"1.3.0", "rc", "1.3.0-rc.1"},
+		{"1.3.0-rc.1", "rc", "1.3.0-rc.2"},
+		{"1.3.0-rc.9", "rc", "1.3.0-rc.10"},
+		{"1.3.0-rc", "rc", "1.3.0-rc.1"},
+		{"1.3.0-alpha.2", "beta", "1.3.0-beta.1"},
+		{"1.3.0-rc.1+build", "rc", "1.3.0-rc.2"},
+	}
+
+	for _, tt := range tests {
+		if got := MustParse(tt.version).BumpPreRelease(tt.id).NoPrefix(); got != tt.want {
+			t.Errorf("%s.BumpPreRelease(%s) = %s, want %s", tt.version, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestValidPreReleaseID(t *testing.T) {
+	tests := []struct {
+		id string
+		valid bool
+	}{
+		{"rc", true},
+		{"beta-2", true},
+		{"0", true},
+		{"7", true},
+		{"07", false},
+		{"rc.1", false},
+		{"", false},
+		{"r c", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidPreReleaseID(tt.id); got != tt.valid {
+			t.Errorf("ValidPreReleaseID(%q) = %t, want %t", tt.id, got, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package tickets
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/common"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+// Validate checks a ticket key against the tracker selected in the GOG configuration
+func Validate(ticket string) error {
+	return validate(config.AppConfig(), ticket)
+}
+
+func validate(c *config.Configuration, ticket string) error {
+	if ticket == "" {
+		return fmt.Errorf("ticket cannot be empty")
+	}
+
+	if c.FreeFormTickets() {
+		logging.Instance().Debugf("free-form tickets enabled, skipping validation for '%s'", ticket)
+		return nil
+	}
+
+	name := c.TicketTracker()
+	tracker, err := c.Tracker()
+	if err != nil {
+		return err
+	}
+
+	if tracker.Pattern == "" {
+		return fmt.Errorf("no ticket pattern configured for %s tracker. set tickets.trackers.%s.pattern", name, name)
+	}
+
+	validTicket, err := regexp.MatchString(tracker.Pattern, ticket)
+	if err != nil {
+		return fmt.Errorf("failed to parse ticket pattern for %s tracker. %v", name, err)
+	}
+
+	if !validTicket {
+		if tracker.Example != "" {
+			return fmt.Errorf("invalid %s ticket '%s' ... example of a valid ticket would be '%s'", name, ticket, tracker.Example)
+		}
+		return fmt.Errorf("invalid %s ticket '%s' ... tickets must match '%s'", name, ticket, tracker.Pattern)
+	}
+
+	if len(tracker.Projects) > 0 {
+		project := strings.SplitN(ticket, "-", 2)[0]
+		if !common.StringInSlice(upper(tracker.Projects), strings.ToUpper(project)) {
+			return fmt.Errorf("ticket '%s' does not belong to an allowed %s project. allowed projects: %s", ticket, name, strings.Join(tracker.Projects, ", "))
+		}
+	}
+
+	logging.Instance().Debugf("validated ticket '%s' against %s tracker", ticket, name)
+
+	return nil
+}
+
+func upper(values []string) []string {
+	upper := make([]string, 0, len(values))
+	for _, v := range values {
+		upper = append(upper, strings.ToUpper(v))
+	}
+	return upper
+}
@@ -0,0 +1,52 @@
+package tickets
+
+import (
+	"testing"
+
+	"sykesdev.ca/gog/config"
+)
+
+func TestValidate(t *testing.T) {
+	custom := map[string]config.Tracker{
+		"jira": {Projects: []string{"abc", "OPS"}},
+		"shortcut": {Pattern: "^sc-[0-9]+$", Example: "sc-12"},
+		"broken": {Pattern: "^[0-9+$"},
+		"nopattern": {},
+	}
+
+	tests := []struct {
+		tracker string
+		trackers map[string]config.Tracker
+		freeForm bool
+		ticket string
+		valid bool
+	}{
+		{"", nil, false, "ABC-123", true},
+		{"", nil, false, "abc-1", true},
+		{"", nil, false, "", false},
+		{"", nil, false, "ABC123", false},
+		{"jira", nil, false, "feature/login", false},
+		{"jira", nil, true, "feature/login", true},
+		{"jira", nil, true, "", false},
+		{"github", nil, false, "GH-42", true},
+		{"github", nil, false, "42", true},
+		{"github", nil, false, "ABC-42", false},
+		{"linear", nil, false, "ENG-7", true},
+		{"jira", custom, false, "ABC-1", true},
+		{"jira", custom, false, "ops-1", true},
+		{"jira", custom, false, "XYZ-1", false},
+		{"shortcut", custom, false, "sc-12", true},
+		{"shortcut", custom, false, "SC-12", false},
+		{"broken", custom, false, "1", false},
+		{"nopattern", custom, false, "1", false},
+		{"unknown", nil, false, "ABC-1", false},
+	}
+
+	for _, tt := range tests {
+		c := &config.Configuration{Tickets: config.Tickets{Tracker: tt.tracker, FreeForm: tt.freeForm, Trackers: tt.trackers}}
+
+		if err := validate(c, tt.ticket); (err == nil) != tt.valid {
+			t.Errorf("validate(%q) with tracker '%s' (free-form: %t) = %v, want valid: %t", tt.ticket, tt.tracker, tt.freeForm, err, tt.valid)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package update
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -10,16 +9,16 @@ import (
 	"runtime"
 	"strings"
 
-	"github.com/google/go-github/v43/github"
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/forge"
 	"sykesdev.ca/gog/internal/logging"
 	"sykesdev.ca/gog/internal/semver"
 )
 
-var ctx = context.Background()
 var Version string
 
 type Updater struct {
-	client *github.Client
+	forge forge.Forge
 
 	currentVersion semver.Semver
 	updateVersion semver.Semver
@@ -27,7 +26,7 @@ type Updater struct {
 	repoOwner string
 	repoName string
 
-	updateRelease *github.RepositoryRelease
+	updateRelease *forge.Release
 
 	binaryOs string
 	binaryArch string
@@ -42,15 +41,30 @@ func NewUpdater(tag string) (*Updater, error) {
 
 	logging.Instance().Debugf("captured GOG executable location from PATH: %s", binaryPath)
 
+	repository := config.AppConfig().UpdateRepository()
+	i := strings.LastIndex(repository, "/")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid update repository '%s'. expected the form owner/name", repository)
+	}
+
 	u := &Updater{
-		client: github.NewClient(nil),
-		repoOwner: "SystemFiles",
-		repoName: "GOG",
+		repoOwner: repository[:i],
+		repoName: repository[i + 1:],
 		binaryOs: runtime.GOOS,
 		binaryArch: runtime.GOARCH,
 		binaryLocation: binaryPath,
 	}
 
+	u.forge, err = forge.NewProvider(forge.Options{
+		Provider: config.AppConfig().UpdateProvider(),
+		BaseURL: config.AppConfig().UpdateBaseURL(),
+		Owner: u.repoOwner,
+		Name: u.repoName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	u.currentVersion = semver.MustParse(Version)
 	if tag == "" {
 		u.updateVersion, err = u.getLatestVersion()
@@ -73,8 +87,8 @@ func NewUpdater(tag string) (*Updater, error) {
 	return u, nil
 }
 
-func (u *Updater) Client() *github.Client {
-	return u.client
+func (u *Updater) Forge() forge.Forge {
+	return u.forge
 }
 
 func (u *Updater) RepoOwner() string {
@@ -106,27 +120,43 @@ func (u *Updater) UpdateVersion() semver.Semver {
 }
 
 func (u *Updater) getLatestVersion() (semver.Semver, error) {
-	releases, _, err := u.client.Repositories.ListReleases(ctx, u.repoOwner, u.repoName, &github.ListOptions{})
+	releases, err := u.forge.ListReleases()
 	if err != nil {
 		return semver.Semver{}, errors.New("failed to list project releases. " + err.Error())
 	}
 
-	logging.Instance().Debugf("latest GOG version captured: %s", *releases[0].TagName)
+	var latest *semver.Semver
+	for _, r := range releases {
+		version, err := semver.Parse(r.Tag)
+		if err != nil || r.PreRelease || version.IsPreRelease() {
+			continue
+		}
 
-	return semver.MustParse(*releases[0].TagName), nil
+		if latest == nil || version.GreaterThan(*latest) {
+			latest = &version
+		}
+	}
+
+	if latest == nil {
+		return semver.Semver{}, errors.New("failed to find a stable GOG release")
+	}
+
+	logging.Instance().Debugf("latest GOG version captured: %s", latest.NoPrefix())
+
+	return *latest, nil
 }
 
 func (u *Updater) getReleaseForVersion(version semver.Semver) error {
-	releases, _, err := u.client.Repositories.ListReleases(ctx, u.repoOwner, u.repoName, &github.ListOptions{})
+	releases, err := u.forge.ListReleases()
 	if err != nil {
 		return errors.New("failed to list project releases. " + err.Error())
 	}
 
-	logging.Instance().Debugf("Full list of GOG releases from Github: %v", releases)
+	logging.Instance().Debugf("Full list of GOG releases from %s: %v", u.forge.Name(), releases)
 
-	for _, r := range releases {
-		if *r.TagName == version.NoPrefix() {
-			u.updateRelease = r
+	for i, r := range releases {
+		if tagVersion, err := semver.Parse(r.Tag); err == nil && tagVersion.Equal(version) {
+			u.updateRelease = &releases[i]
 			break
 		}
 	}
@@ -135,29 +165,29 @@ func (u *Updater) getReleaseForVersion(version semver.Semver) error {
 		return fmt.Errorf("failed to locate the specified version (%s) in project releases", version)
 	}
 
-	logging.Instance().Debugf("target release for GOG update: %s", u.updateRelease)
+	logging.Instance().Debugf("target release for GOG update: %s (%s)", u.updateRelease.Tag, u.updateRelease.URL)
 
 	return nil
 }
 
-func (u *Updater) getLatestReleaseAsset() (*github.ReleaseAsset, error) {
+func (u *Updater) getLatestReleaseAsset() (*forge.Asset, error) {
 	if u.updateRelease == nil {
 		return nil, errors.New("cannot get latest release asset since 'latestRelease' is not defined")
 	}
 
 	logging.Instance().Debug("searching project release assets for compatible binary release")
 
-	for _, asset := range u.updateRelease.Assets {
-		logging.Instance().Debugf("processing asset: %s", *asset.Name)
+	for i, asset := range u.updateRelease.Assets {
+		logging.Instance().Debugf("processing asset: %s", asset.Name)
 
 		extension := "tar.gz"
 		if u.binaryOs == "windows" {
 			extension = "zip"
 		}
 
-		if strings.Contains(*asset.Name, fmt.Sprintf("%s-%s-%s-%s.%s", u.repoName, u.updateVersion.NoPrefix(), u.binaryOs, u.binaryArch, extension)) {
-			logging.Instance().Debugf("found matching release asset for the current system: %s", *asset.Name)
-			return asset, nil
+		if strings.Contains(asset.Name, fmt.Sprintf("%s-%s-%s-%s.%s", u.repoName, u.updateVersion.NoPrefix(), u.binaryOs, u.binaryArch, extension)) {
+			logging.Instance().Debugf("found matching release asset for the current system: %s", asset.Name)
+			return &u.updateRelease.Assets[i], nil
 		}
 	}
 
@@ -169,7 +199,7 @@ func (u *Updater) downloadLatestReleaseBinary() (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
-	downloadUrl := asset.GetBrowserDownloadURL()
+	downloadUrl := asset.URL
 
 	logging.Instance().Debugf("download URL for GOG release asset: %s", downloadUrl)
 
@@ -178,13 +208,13 @@ func (u *Updater) downloadLatestReleaseBinary() (io.ReadCloser, error) {
     return nil, err
   }
 
-	logging.Instance().Debugf("download completed for GOG release asset (%s). Downloaded total: %d bytes", *asset.Name, resp.ContentLength)
+	logging.Instance().Debugf("download completed for GOG release asset (%s). Downloaded total: %d bytes", asset.Name, resp.ContentLength)
 
 	return resp.Body, nil
 }
 
 func (u *Updater) Update() error {
-	if u.currentVersion == u.updateVersion {
+	if u.currentVersion.Equal(u.updateVersion) {
 		return errors.New("GOG is already at the latest version")
 	}
 
@@ -0,0 +1,72 @@
+package versionfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContext = 3
+
+// Diff renders the edit as a unified diff. the changed lines are kept in a single hunk, which suits the small edits made to version files
+func (e *Edit) Diff() string {
+	if !e.Changed() {
+		return ""
+	}
+
+	before, after := splitLines(e.Before), splitLines(e.After)
+
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(before) - prefix && suffix < len(after) - prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	start := prefix - diffContext
+	if start < 0 {
+		start = 0
+	}
+	beforeEnd, afterEnd := len(before) - suffix + diffContext, len(after) - suffix + diffContext
+	if beforeEnd > len(before) {
+		beforeEnd = len(before)
+	}
+	if afterEnd > len(after) {
+		afterEnd = len(after)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", e.Path, e.Path)
+	fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(start, beforeEnd - start), hunkRange(start, afterEnd - start))
+
+	for _, line := range before[start:prefix] {
+		fmt.Fprintf(&b, " %s\n", line)
+	}
+	for _, line := range before[prefix:len(before)-suffix] {
+		fmt.Fprintf(&b, "-%s\n", line)
+	}
+	for _, line := range after[prefix:len(after)-suffix] {
+		fmt.Fprintf(&b, "+%s\n", line)
+	}
+	for _, line := range before[len(before)-suffix:beforeEnd] {
+		fmt.Fprintf(&b, " %s\n", line)
+	}
+
+	return b.String()
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start + 1, length)
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
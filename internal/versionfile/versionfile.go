@@ -0,0 +1,384 @@
+package versionfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	"sykesdev.ca/gog/config"
+	"sykesdev.ca/gog/internal/logging"
+)
+
+// Edit is the new content of a version file, along with what it held before
+type Edit struct {
+	Path string `json:"path"`
+	Before string `json:"-"`
+	After string `json:"-"`
+}
+
+// Plan works out the edits which set version in each of files, relative to root, without writing them
+func Plan(root string, files []config.VersionFile, version string) ([]*Edit, error) {
+	var edits []*Edit
+	byPath := map[string]*Edit{}
+
+	for _, f := range files {
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+
+		e, ok := byPath[f.Path]
+		if !ok {
+			content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read version file %s. %v", f.Path, err)
+			}
+
+			e = &Edit{Path: f.Path, Before: string(content), After: string(content)}
+			byPath[f.Path] = e
+			edits = append(edits, e)
+		}
+
+		updated, err := replace(f, e.After, version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update version file %s. %v", f.Path, err)
+		}
+		e.After = updated
+
+		logging.Instance().Debugf("planned version %s for %s", version, f.Path)
+	}
+
+	return edits, nil
+}
+
+// Write saves the edits which change their file
+func Write(root string, edits []*Edit) error {
+	for _, e := range edits {
+		if !e.Changed() {
+			continue
+		}
+
+		path := filepath.Join(root, filepath.FromSlash(e.Path))
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(path, []byte(e.After), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write version file %s. %v", e.Path, err)
+		}
+	}
+	return nil
+}
+
+func (e *Edit) Changed() bool {
+	return e.Before != e.After
+}
+
+func replace(f config.VersionFile, content, version string) (string, error) {
+	if f.Pattern != "" {
+		return replacePattern(f.Pattern, content, version)
+	}
+
+	path := strings.Split(f.Key, ".")
+
+	var start, end int
+	var value string
+	var err error
+	switch f.FileFormat() {
+	case config.FormatJSON:
+		start, end, err = jsonValue(content, path)
+		value = fmt.Sprintf("%q", version)
+	case config.FormatYAML:
+		start, end, value, err = yamlValue(content, path, version)
+	case config.FormatTOML:
+		start, end, err = tomlValue(content, path)
+		value = content[start:start+1] + version + content[start:start+1]
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return content[:start] + value + content[end:], nil
+}
+
+// replacePattern replaces the first capture group of the first match, or the whole match when there is no group
+func replacePattern(pattern, content, version string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	match := re.FindStringSubmatchIndex(content)
+	if match == nil {
+		return "", fmt.Errorf("pattern '%s' did not match", pattern)
+	}
+
+	start, end := match[0], match[1]
+	if len(match) > 2 && match[2] >= 0 {
+		start, end = match[2], match[3]
+	}
+
+	return content[:start] + version + content[end:], nil
+}
+
+type jsonScanner struct {
+	data string
+	pos int
+}
+
+// jsonValue finds the span of the value at path, keeping the rest of the document byte for byte
+func jsonValue(content string, path []string) (int, int, error) {
+	s := &jsonScanner{data: content}
+	start, end, err := s.find(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !strings.HasPrefix(content[start:end], `"`) {
+		return 0, 0, fmt.Errorf("key '%s' does not hold a string", strings.Join(path, "."))
+	}
+	return start, end, nil
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) && strings.ContainsRune(" \t\r\n", rune(s.data[s.pos])) {
+		s.pos++
+	}
+}
+
+func (s *jsonScanner) find(path []string) (int, int, error) {
+	s.skipSpace()
+	if len(path) == 0 {
+		start := s.pos
+		if err := s.skipValue(); err != nil {
+			return 0, 0, err
+		}
+		return start, s.pos, nil
+	}
+
+	if s.pos >= len(s.data) || s.data[s.pos] != '{' {
+		return 0, 0, fmt.Errorf("key '%s' not found", path[0])
+	}
+	s.pos++
+
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.data) || s.data[s.pos] == '}' {
+			return 0, 0, fmt.Errorf("key '%s' not found", path[0])
+		}
+
+		keyStart := s.pos
+		if err := s.skipString(); err != nil {
+			return 0, 0, err
+		}
+
+		var key string
+		if err := json.Unmarshal([]byte(s.data[keyStart:s.pos]), &key); err != nil {
+			return 0, 0, fmt.Errorf("invalid JSON. %v", err)
+		}
+
+		s.skipSpace()
+		if s.pos >= len(s.data) || s.data[s.pos] != ':' {
+			return 0, 0, fmt.Errorf("invalid JSON. expected ':' at offset %d", s.pos)
+		}
+		s.pos++
+
+		if key == path[0] {
+			return s.find(path[1:])
+		}
+
+		s.skipSpace()
+		if err := s.skipValue(); err != nil {
+			return 0, 0, err
+		}
+
+		s.skipSpace()
+		if s.pos < len(s.data) && s.data[s.pos] == ',' {
+			s.pos++
+		}
+	}
+}
+
+func (s *jsonScanner) skipString() error {
+	if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+		return fmt.Errorf("invalid JSON. expected a string at offset %d", s.pos)
+	}
+
+	for s.pos++; s.pos < len(s.data); s.pos++ {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos++
+		case '"':
+			s.pos++
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid JSON. unterminated string")
+}
+
+func (s *jsonScanner) skipValue() error {
+	if s.pos >= len(s.data) {
+		return fmt.Errorf("invalid JSON. unexpected end of document")
+	}
+
+	switch s.data[s.pos] {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.data) {
+			switch s.data[s.pos] {
+			case '"':
+				if err := s.skipString(); err != nil {
+					return err
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+			s.pos++
+
+			if depth == 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid JSON. unterminated object or array")
+	}
+
+	for s.pos < len(s.data) && !strings.ContainsRune(",}] \t\r\n", rune(s.data[s.pos])) {
+		s.pos++
+	}
+	return nil
+}
+
+// yamlValue finds the scalar at path and renders version in the same quoting style
+func yamlValue(content string, path []string, version string) (int, int, string, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte(content), &doc); err != nil {
+		return 0, 0, "", fmt.Errorf("invalid YAML. %v", err)
+	}
+	if len(doc.Content) == 0 {
+		return 0, 0, "", fmt.Errorf("key '%s' not found", strings.Join(path, "."))
+	}
+
+	node := doc.Content[0]
+	for _, part := range path {
+		var next *yamlv3.Node
+		if node.Kind == yamlv3.MappingNode {
+			for i := 0; i + 1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == part {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		}
+		if next == nil {
+			return 0, 0, "", fmt.Errorf("key '%s' not found", strings.Join(path, "."))
+		}
+		node = next
+	}
+
+	if node.Kind != yamlv3.ScalarNode || strings.Contains(node.Value, "\n") {
+		return 0, 0, "", fmt.Errorf("key '%s' does not hold a single line value", strings.Join(path, "."))
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	offset := 0
+	for _, line := range lines[:node.Line-1] {
+		offset += len(line)
+	}
+	start := offset + len(string([]rune(lines[node.Line-1])[:node.Column-1]))
+
+	switch {
+	case node.Style&yamlv3.DoubleQuotedStyle != 0:
+		end, err := closingQuote(content, start, '"', true)
+		return start, end, fmt.Sprintf("%q", version), err
+	case node.Style&yamlv3.SingleQuotedStyle != 0:
+		end, err := closingQuote(content, start, '\'', false)
+		return start, end, "'" + version + "'", err
+	}
+	return start, start + len(node.Value), version, nil
+}
+
+// tomlValue finds the quoted string at path. keys may be dotted and tables are read from [table] headers
+func tomlValue(content string, path []string) (int, int, error) {
+	want := strings.Join(path, ".")
+	table := ""
+	offset := 0
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "[["):
+			// entries of an array of tables cannot be addressed by a key path
+			table = "\x00"
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			table = tomlKey(strings.TrimSuffix(strings.SplitN(trimmed[1:], "]", 2)[0], "]"))
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			continue
+		}
+
+		key := tomlKey(line[:eq])
+		if table != "" {
+			key = table + "." + key
+		}
+		if key != want {
+			continue
+		}
+
+		start := eq + 1
+		for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+			start++
+		}
+		if start >= len(line) || (line[start] != '"' && line[start] != '\'') {
+			return 0, 0, fmt.Errorf("key '%s' does not hold a string", want)
+		}
+
+		end, err := closingQuote(line, start, line[start], line[start] == '"')
+		if err != nil {
+			return 0, 0, err
+		}
+		return lineStart + start, lineStart + end, nil
+	}
+
+	return 0, 0, fmt.Errorf("key '%s' not found", want)
+}
+
+func tomlKey(raw string) string {
+	parts := strings.Split(strings.TrimSpace(raw), ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// closingQuote returns the offset just past the quote closing the string which opens at start
+func closingQuote(s string, start int, quote byte, escapes bool) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case escapes && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			return i + 1, nil
+		case s[i] == '\n':
+			return 0, fmt.Errorf("unterminated string")
+		}
+	}
+	return 0, fmt.Errorf("unterminated string")
+}
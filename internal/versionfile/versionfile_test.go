@@ -0,0 +1,95 @@
+package versionfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sykesdev.ca/gog/config"
+)
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		name string
+		file config.VersionFile
+		content string
+		want string
+		valid bool
+	}{
+		{"pattern group", config.VersionFile{Path: "version.go", Pattern: `Version = "([^"]+)"`}, "package app\n\nconst Version = \"1.2.0\"\n", "package app\n\nconst Version = \"1.3.0\"\n", true},
+		{"pattern without group", config.VersionFile{Path: "VERSION", Pattern: `[0-9]+\.[0-9]+\.[0-9]+`}, "1.2.0\n", "1.3.0\n", true},
+		{"pattern first match only", config.VersionFile{Path: "x.txt", Pattern: `v=(\S+)`}, "v=1\nv=2\n", "v=1.3.0\nv=2\n", true},
+		{"pattern without match", config.VersionFile{Path: "x.txt", Pattern: `version: (\S+)`}, "name: app\n", "", false},
+		{"json", config.VersionFile{Path: "package.json", Key: "version"}, "{\n  \"name\": \"app\",\n  \"version\": \"1.2.0\",\n  \"deps\": {\"version\": \"9\"}\n}\n", "{\n  \"name\": \"app\",\n  \"version\": \"1.3.0\",\n  \"deps\": {\"version\": \"9\"}\n}\n", true},
+		{"json nested", config.VersionFile{Path: "app.json", Key: "expo.version"}, `{"name":"a\"}","expo":{"sdk":[1,{"version":"x"}],"version":"1.2.0"}}`, `{"name":"a\"}","expo":{"sdk":[1,{"version":"x"}],"version":"1.3.0"}}`, true},
+		{"json not a string", config.VersionFile{Path: "package.json", Key: "version"}, `{"version": 1}`, "", false},
+		{"json missing key", config.VersionFile{Path: "package.json", Key: "version"}, `{"name": "app"}`, "", false},
+		{"yaml plain", config.VersionFile{Path: "Chart.yaml", Key: "version"}, "apiVersion: v2\nversion: 1.2.0 # chart\nappVersion: \"1.2.0\"\n", "apiVersion: v2\nversion: 1.3.0 # chart\nappVersion: \"1.2.0\"\n", true},
+		{"yaml double quoted", config.VersionFile{Path: "Chart.yaml", Key: "appVersion"}, "version: 1.2.0\nappVersion: \"1.2.0\"\n", "version: 1.2.0\nappVersion: \"1.3.0\"\n", true},
+		{"yaml single quoted nested", config.VersionFile{Path: "values.yml", Key: "image.tag"}, "image:\n  repo: app\n  tag: '1.2.0'\n", "image:\n  repo: app\n  tag: '1.3.0'\n", true},
+		{"yaml not a scalar", config.VersionFile{Path: "values.yml", Key: "image"}, "image:\n  tag: 1\n", "", false},
+		{"toml table", config.VersionFile{Path: "Cargo.toml", Key: "package.version"}, "[package]\nname = \"app\"\nversion = \"1.2.0\"\n\n[dependencies]\nversion = \"9\"\n", "[package]\nname = \"app\"\nversion = \"1.3.0\"\n\n[dependencies]\nversion = \"9\"\n", true},
+		{"toml dotted key", config.VersionFile{Path: "pyproject.toml", Key: "tool.poetry.version"}, "[tool]\npoetry.version = '1.2.0'\n", "[tool]\npoetry.version = '1.3.0'\n", true},
+		{"toml array of tables", config.VersionFile{Path: "x.toml", Key: "bin.version"}, "[[bin]]\nversion = \"1.2.0\"\n", "", false},
+		{"toml not a string", config.VersionFile{Path: "x.toml", Key: "version"}, "version = 1\n", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := replace(tt.file, tt.content, "1.3.0")
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: replace error = %v, want valid: %t", tt.name, err, tt.valid)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: replace = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlanAndWrite(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"package.json": "{\n  \"version\": \"1.2.0\"\n}\n",
+		"Chart.yaml": "version: 1.2.0\nappVersion: 1.2.0\n",
+		"VERSION": "1.3.0\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	edits, err := Plan(root, []config.VersionFile{
+		{Path: "package.json", Key: "version"},
+		{Path: "Chart.yaml", Key: "version"},
+		{Path: "Chart.yaml", Key: "appVersion"},
+		{Path: "VERSION", Pattern: `.+`},
+	}, "1.3.0")
+	if err != nil {
+		t.Fatalf("Plan failed. %v", err)
+	}
+
+	// both keys of Chart.yaml are planned as a single edit, and VERSION already holds the version
+	if len(edits) != 3 || !edits[0].Changed() || !edits[1].Changed() || edits[2].Changed() {
+		t.Fatalf("unexpected edits %+v", edits)
+	}
+	if !strings.Contains(edits[1].Diff(), "+appVersion: 1.3.0") {
+		t.Errorf("Chart.yaml diff = %s", edits[1].Diff())
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(root, "Chart.yaml")); string(content) != files["Chart.yaml"] {
+		t.Error("Plan should not write the version files")
+	}
+
+	if err := Write(root, edits); err != nil {
+		t.Fatalf("Write failed. %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "Chart.yaml")); string(content) != "version: 1.3.0\nappVersion: 1.3.0\n" {
+		t.Errorf("Chart.yaml = %q", content)
+	}
+
+	if _, err := Plan(root, []config.VersionFile{{Path: "missing.json", Key: "version"}}, "1.3.0"); err == nil {
+		t.Error("Plan should fail for a missing version file")
+	}
+}
@@ -1,25 +1,65 @@
 package main
 
 import (
-	"errors"
-	"fmt"
 	"os"
+	"strings"
 
 	"sykesdev.ca/gog/cmd"
 	"sykesdev.ca/gog/config"
 	"sykesdev.ca/gog/internal/common"
 	"sykesdev.ca/gog/internal/logging"
+	"sykesdev.ca/gog/internal/output"
+	"sykesdev.ca/gog/internal/prompt"
 	"sykesdev.ca/gog/internal/update"
 )
 
-func root() error {
+type versionResult struct {
+	Version string `json:"version"`
+}
+
+type globalOptions struct {
+	output string
+	yes bool
+	noInput bool
+}
+
+// globalFlags reads the global flags (--output, --yes and --no-input) given before the sub-command. scanning stops at the
+// sub-command, so that its own arguments (eg. a commit message mentioning --yes) are passed through untouched
+func globalFlags(args []string) ([]string, *globalOptions, error) {
+	opts := &globalOptions{output: output.FormatText}
+	remaining := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--output" || arg == "-output":
+			if i+1 >= len(args) {
+				return nil, nil, output.Errorf(output.CodeUsage, "%s requires a format (one of %s)", arg, strings.Join(output.Formats, ", "))
+			}
+			opts.output = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--output=") || strings.HasPrefix(arg, "-output="):
+			opts.output = arg[strings.Index(arg, "=")+1:]
+		case arg == "--yes" || arg == "-yes":
+			opts.yes = true
+		case arg == "--no-input" || arg == "-no-input":
+			opts.noInput = true
+		default:
+			return append(remaining, args[i:]...), opts, nil
+		}
+	}
+
+	return remaining, opts, nil
+}
+
+func root() (string, interface{}, error) {
 	if len(os.Args[1:]) < 1 {
-		return errors.New("you must pass a sub-command\nUsage: gog <feature(feat) | push(p) | finish(fin) | update | simple-push(sp)> [options ...] [-h] [-help]")
+		return "", nil, output.Errorf(output.CodeUsage, "you must pass a sub-command\nUsage: gog [--output text|json] [--yes | --no-input] <feature(feat) | push(p) | finish(fin) | update | simple-push(sp) | release | config | status(st) | changelog(cl) | verify> [options ...] [-h] [-help]")
 	}
 
 	if common.StringInSlice(os.Args, "-v") || common.StringInSlice(os.Args, "-version") {
 		logging.Instance().Infof("Current Version of GOG: %s", update.Version)
-		return nil
+		return "version", &versionResult{Version: update.Version}, nil
 	}
 
 	cmds := []cmd.Runnable {
@@ -28,6 +68,11 @@ func root() error {
 		cmd.NewFinishCommand(),
 		cmd.NewUpdateSelfCommand(),
 		cmd.NewSimplePushCommand(),
+		cmd.NewReleaseCommand(),
+		cmd.NewConfigCommand(),
+		cmd.NewStatusCommand(),
+		cmd.NewChangelogCommand(),
+		cmd.NewVerifyCommand(),
 	}
 
 	subcommand := os.Args[1]
@@ -36,22 +81,61 @@ func root() error {
 		if cmd.Name() == subcommand || cmd.Alias() == subcommand {
 			if common.StringInSlice(os.Args, "-h") || common.StringInSlice(os.Args, "-help") {
 				cmd.Help()
-				return nil
+				return cmd.Name(), nil, nil
 			}
+			// the config command still runs, so that it can repair the configuration
+			if err := config.Load(); err != nil && cmd.Name() != "config" {
+				return cmd.Name(), nil, output.Errorf(output.CodeConfig, "failed to load configuration. %v", err)
+			}
+
 			if err := cmd.Init(os.Args[2:]); err != nil {
-				return err
+				return cmd.Name(), nil, output.WithCode(output.CodeUsage, err)
 			}
-			return cmd.Run()
+
+			result, err := cmd.Run()
+			return cmd.Name(), result, err
 		}
 	}
 
-	return fmt.Errorf("unknown subcommand: %s", subcommand)
+	return subcommand, nil, output.Errorf(output.CodeUsage, "unknown subcommand: %s", subcommand)
 }
 
 func main() {
+	args, opts, err := globalFlags(os.Args[1:])
+	if err == nil {
+		err = output.SetFormat(opts.output)
+	}
+
+	// a configuration which cannot be loaded is reported by the sub-command, and the defaults are used until then
+	config.Load()
 	logging.Instance().Setup(config.AppConfig().LogLevel())
 
-	if err := root(); err != nil {
+	if err != nil {
+		logging.Instance().Error(err.Error())
+		os.Exit(1)
+	}
+	os.Args = append(os.Args[:1], args...)
+
+	prompt.SetAssumeYes(opts.yes)
+	prompt.SetNoInput(opts.noInput)
+
+	if output.JSON() {
+		logging.Instance().SetOutput(os.Stderr)
+	}
+
+	command, result, err := root()
+
+	if output.JSON() {
+		if printErr := output.Print(os.Stdout, command, result, err); printErr != nil {
+			logging.Instance().Errorf("failed to print result. %v", printErr)
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err != nil {
 		logging.Instance().Error(err.Error())
 		os.Exit(1)
 	}